@@ -0,0 +1,212 @@
+// Package builder constructs the tutorial MCP server once for every transport.
+//
+// Tools, prompts and resources are registered in New only, so the stdio,
+// sse and streamable_http transports cannot drift apart.
+package builder
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"tutorial"
+	"tutorial/mcp"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Server MCP server with every tool, prompt and resource registered, ready to be served on one or more transports
+type Server struct {
+	MCPServer *server.MCPServer
+	config    Config
+	metrics   *mcp.Metrics
+	logger    *slog.Logger
+	// watchers Background loops keeping prompts and file resources in sync, started by Serve
+	watchers []func(ctx context.Context)
+}
+
+// New Builds the MCP server for a configuration
+func New(config Config, logger *slog.Logger) (*Server, error) {
+	sessions := mcp.NewSessionTracker()
+	hooks := &server.Hooks{}
+	sessions.Register(hooks)
+
+	subscriptions := mcp.NewResourceSubscriptions()
+	subscriptions.Register(hooks)
+
+	metrics := mcp.NewMetrics(sessions)
+	metrics.Register(hooks)
+
+	completions := mcp.NewCompletions()
+
+	mcpServer := server.NewMCPServer(
+		config.Server.Name,
+		config.Server.Version,
+		server.WithLogging(),
+		server.WithHooks(hooks),
+		server.WithPaginationLimit(config.Server.PageSize),
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(true),
+		server.WithCompletions(),
+		server.WithResourceCompletionProvider(completions),
+		server.WithPromptCompletionProvider(completions),
+	)
+
+	s := &Server{
+		MCPServer: mcpServer,
+		config:    config,
+		metrics:   metrics,
+		logger:    logger,
+	}
+
+	// Templates are grouped by the resource that provides them, so that prompt reloads replace only the previews
+	templates := mcp.NewResourceTemplates(mcpServer, completions, metrics)
+
+	location := time.Local
+	if zone := config.Tools.SystemInfo.TimeZone; zone != "" {
+		l, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid system_info time zone: %w", err)
+		}
+		location = l
+	}
+	tools := []server.ServerTool{
+		mcp.CalculatorTool(config.Tools.Calculator.Precision),
+		mcp.SystemInfoTool(location),
+		mcp.GoTestScaffoldTool(config.Tools.GoTestScaffold.MaxSourceBytes),
+	}
+	for _, tool := range tools {
+		if enabled(config.Tools.Enabled, tool.Tool.Name) {
+			mcpServer.AddTools(tool)
+		}
+	}
+
+	promptSources := []fs.FS{mcp.BuiltinPrompts()}
+	if dir := config.Prompts.Dir; dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("invalid prompts dir %q: not a directory", dir)
+		}
+		promptSources = append(promptSources, os.DirFS(dir))
+	}
+
+	prompting := config.Prompts
+	argumentPolicy, err := mcp.NewArgumentPolicy(prompting.InjectionPolicy, prompting.FenceArguments, prompting.ArgumentMaxLength, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	prompts, err := mcp.NewPromptRegistry(mcpServer, completions, prompting.Variants, prompting.Enabled, argumentPolicy, logger, promptSources...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+	s.watchers = append(s.watchers, func(ctx context.Context) {
+		if err := prompts.Run(ctx, prompting.Dir); err != nil {
+			logger.Error("Prompt reloader stopped", "error", err)
+		}
+	})
+
+	served := prompts.Names()
+	var gitReview *server.ServerPrompt
+	if len(prompting.GitRoots) > 0 && enabled(prompting.Enabled, "git_review") {
+		gitRoots, err := mcp.NewGitRoots(prompting.GitRoots...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid git roots: %w", err)
+		}
+		prompt := mcp.GitReviewPrompt(prompts, gitRoots, completions)
+		gitReview = &prompt
+		mcpServer.AddPrompts(prompt)
+		served = append(served, prompt.Prompt.Name)
+	}
+	var unknown []string
+	for _, name := range prompting.Enabled {
+		if !slices.Contains(served, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown prompts in prompts.enabled: %s", strings.Join(unknown, ", "))
+	}
+
+	resources := config.Resources
+	if enabled(resources.Enabled, "prompt_previews") {
+		prompts.ServePreviews(templates)
+		if gitReview != nil {
+			templates.Add("git_review", mcp.PromptPreviewTemplates(completions, gitReview.Prompt)...)
+		}
+	}
+	if enabled(resources.Enabled, "system_status") {
+		mcpServer.AddResources(mcp.SystemStatusResource(config.Server.Name, config.Server.Version, sessions, templates))
+	}
+	if enabled(resources.Enabled, "math_constants") {
+		mcpServer.AddResources(mcp.MathConstantsResource())
+		templates.Add("math_constants", mcp.MathConstantTemplate(completions))
+	}
+	if enabled(resources.Enabled, "physical_constants") {
+		mcpServer.AddResources(mcp.PhysicalConstantsResource())
+		templates.Add("physical_constants", mcp.PhysicalConstantsTemplate(completions))
+	}
+	if enabled(resources.Enabled, "metrics") {
+		mcpServer.AddResources(mcp.MetricsResource(metrics))
+	}
+	if enabled(resources.Enabled, "prompt_locales") {
+		mcpServer.AddResources(prompts.LocalesResource())
+	}
+
+	if enabled(resources.Enabled, "formula_sheets") {
+		formulaSheets, err := mcp.FormulaSheetResources()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load formula sheets: %w", err)
+		}
+		mcpServer.AddResources(formulaSheets...)
+		templates.Add("formula_sheets", mcp.FormulaSheetTemplate(completions))
+	}
+
+	if enabled(resources.Enabled, "docs") {
+		docSources := []fs.FS{tutorial.Docs}
+		if dir := resources.DocsDir; dir != "" {
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				return nil, fmt.Errorf("invalid docs dir %q: not a directory", dir)
+			}
+			docSources = append(docSources, os.DirFS(dir))
+		}
+
+		docs, err := mcp.NewDocLibrary(docSources...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load docs: %w", err)
+		}
+		mcpServer.AddResources(docs.Resources()...)
+		templates.Add("docs", docs.SectionTemplate(completions))
+	}
+
+	if root := resources.Dir; root != "" {
+		fileResources, err := mcp.NewFileResources(root, resources.MaxBytes, resources.IncludeHidden)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure file resources: %w", err)
+		}
+
+		watcher, err := mcp.NewFileWatcher(fileResources, mcpServer, subscriptions, time.Duration(resources.WatchDebounce), logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load file resources: %w", err)
+		}
+		s.watchers = append(s.watchers, func(ctx context.Context) {
+			if err := watcher.Run(ctx); err != nil {
+				logger.Error("File watcher stopped", "error", err)
+			}
+		})
+
+		logger.Info("Serving file resources", "root", fileResources.Root(), "files", watcher.Files(), "max_bytes", resources.MaxBytes)
+	}
+
+	return s, nil
+}
+
+// enabled Reports whether name is selected by a list of enabled names, where nil selects everything
+func enabled(names []string, name string) bool {
+	return names == nil || slices.Contains(names, name)
+}
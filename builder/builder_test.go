@@ -0,0 +1,102 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// listPage One page of a list method, decoded into result
+func listPage(t *testing.T, mcpServer *server.MCPServer, method, cursor string, result any) {
+	t.Helper()
+	params := map[string]any{}
+	if cursor != "" {
+		params["cursor"] = cursor
+	}
+	request, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(mcpServer.HandleMessage(context.Background(), request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := struct {
+		Result any `json:"result"`
+	}{Result: result}
+	if err := json.Unmarshal(encoded, &response); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewPagination(t *testing.T) {
+	config := DefaultConfig()
+	config.Server.PageSize = 2
+	s, err := New(config, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		page   func(cursor string) ([]string, mcp.Cursor)
+		want   []string
+	}{
+		{
+			method: "tools/list",
+			page: func(cursor string) ([]string, mcp.Cursor) {
+				var result mcp.ListToolsResult
+				listPage(t, s.MCPServer, "tools/list", cursor, &result)
+				var names []string
+				for _, tool := range result.Tools {
+					names = append(names, tool.Name)
+				}
+				return names, result.NextCursor
+			},
+			want: slices.Sorted(maps.Keys(s.MCPServer.ListTools())),
+		},
+		{
+			method: "resources/list",
+			page: func(cursor string) ([]string, mcp.Cursor) {
+				var result mcp.ListResourcesResult
+				listPage(t, s.MCPServer, "resources/list", cursor, &result)
+				var uris []string
+				for _, resource := range result.Resources {
+					uris = append(uris, resource.URI)
+				}
+				return uris, result.NextCursor
+			},
+			// Every resource is listed, which needs their names to differ
+			want: slices.Sorted(maps.Keys(s.MCPServer.ListResources())),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			var listed []string
+			cursor := ""
+			for {
+				entries, next := tt.page(cursor)
+				if len(entries) > config.Server.PageSize {
+					t.Fatalf("page after %q has %d entries, want at most %d", cursor, len(entries), config.Server.PageSize)
+				}
+				listed = append(listed, entries...)
+				if next == "" {
+					break
+				}
+				cursor = string(next)
+			}
+			slices.Sort(listed)
+			if !slices.Equal(listed, tt.want) {
+				t.Errorf("listed %v, want %v", listed, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"tutorial/mcp"
+)
+
+// Config Settings of the server shared by every transport, read from a config file and overridden by the environment and flags
+type Config struct {
+	// Server Identity reported to clients and list paging
+	Server ServerConfig `json:"server"`
+	// Transports Transports served and how they listen
+	Transports TransportsConfig `json:"transports"`
+	// Log Level and format of the server log
+	Log LogConfig `json:"log"`
+	// Tools Tools served and their options
+	Tools ToolsConfig `json:"tools"`
+	// Prompts Prompts served and how their arguments are handled
+	Prompts PromptsConfig `json:"prompts"`
+	// Resources Resources served and the directories they come from
+	Resources ResourcesConfig `json:"resources"`
+}
+
+// ServerConfig Identity of the server
+type ServerConfig struct {
+	// Name Server name reported in the initialize result
+	Name string `json:"name"`
+	// Version Server version reported in the initialize result, by default the build version, see mcp.BuildVersion
+	Version string `json:"version"`
+	// PageSize Items per page of list results
+	PageSize int `json:"page_size"`
+}
+
+// TransportsConfig Transports served by the process
+type TransportsConfig struct {
+	// Serve Transports served when none are given on the command line
+	Serve []Transport `json:"serve"`
+	// Failure What a failing transport does to the others served by the process
+	Failure FailurePolicy `json:"failure"`
+	// SSE Settings of the sse transport
+	SSE SSEConfig `json:"sse"`
+	// StreamableHTTP Settings of the streamable_http transport
+	StreamableHTTP StreamableHTTPConfig `json:"streamable_http"`
+}
+
+// SSEConfig Settings of the sse transport
+type SSEConfig struct {
+	// Address Listen address; HTTP transports with the same address share one listener
+	Address string `json:"address"`
+	// KeepAlive Sends keepalive events on idle streams
+	KeepAlive bool `json:"keepalive"`
+	// KeepAliveInterval Time between keepalive events
+	KeepAliveInterval Duration `json:"keepalive_interval"`
+}
+
+// StreamableHTTPConfig Settings of the streamable_http transport
+type StreamableHTTPConfig struct {
+	// Address Listen address; HTTP transports with the same address share one listener
+	Address string `json:"address"`
+	// Stateless Serves every request without a session
+	Stateless bool `json:"stateless"`
+}
+
+// LogConfig Settings of the server log
+type LogConfig struct {
+	// Level Lowest level logged: debug, info, warn or error
+	Level string `json:"level"`
+	// Format Line format: text or json
+	Format string `json:"format"`
+}
+
+// ToolsConfig Tools served and their options
+type ToolsConfig struct {
+	// Enabled Names of the tools served, all when nil
+	Enabled []string `json:"enabled"`
+	// Calculator Options of the calculator tool
+	Calculator CalculatorConfig `json:"calculator"`
+	// SystemInfo Options of the system_info tool
+	SystemInfo SystemInfoConfig `json:"system_info"`
+	// GoTestScaffold Options of the go_test_scaffold tool
+	GoTestScaffold GoTestScaffoldConfig `json:"go_test_scaffold"`
+}
+
+// CalculatorConfig Options of the calculator tool
+type CalculatorConfig struct {
+	// Precision Decimal places of results
+	Precision int `json:"precision"`
+}
+
+// SystemInfoConfig Options of the system_info tool
+type SystemInfoConfig struct {
+	// TimeZone IANA time zone of reported times, the host's when empty
+	TimeZone string `json:"time_zone"`
+}
+
+// GoTestScaffoldConfig Options of the go_test_scaffold tool
+type GoTestScaffoldConfig struct {
+	// MaxSourceBytes Largest source file accepted
+	MaxSourceBytes int `json:"max_source_bytes"`
+}
+
+// PromptsConfig Prompts served and how their arguments are handled
+type PromptsConfig struct {
+	// Enabled Names of the prompts served, all when nil
+	Enabled []string `json:"enabled"`
+	// Dir Directory of prompt files added to the built-in ones and watched for changes
+	Dir string `json:"dir"`
+	// Variants Versions served by unversioned prompt names, see PromptLibrary.ConfigureVariants
+	Variants string `json:"variants"`
+	// InjectionPolicy Action on argument values that look like prompt injection
+	InjectionPolicy string `json:"injection_policy"`
+	// FenceArguments Wraps free-form argument values in <user-input> tags
+	FenceArguments bool `json:"fence_arguments"`
+	// ArgumentMaxLength Length limit of free-form argument values without their own max_length
+	ArgumentMaxLength int `json:"argument_max_length"`
+	// GitRoots Directories whose repositories the git_review prompt may read, disabled when empty
+	GitRoots []string `json:"git_roots"`
+}
+
+// ResourcesConfig Resources served and the directories they come from
+type ResourcesConfig struct {
+	// Enabled Resource groups served, all when nil, see ResourceGroups
+	Enabled []string `json:"enabled"`
+	// Dir Directory exposed as workspace:/// resources, disabled when empty
+	Dir string `json:"dir"`
+	// MaxBytes Largest file served from Dir
+	MaxBytes int64 `json:"max_bytes"`
+	// IncludeHidden Serves hidden files and directories of Dir, such as .git/ and .env
+	IncludeHidden bool `json:"include_hidden"`
+	// WatchDebounce Window in which changes below Dir are coalesced
+	WatchDebounce Duration `json:"watch_debounce"`
+	// DocsDir Directory of markdown docs added to the embedded ones
+	DocsDir string `json:"docs_dir"`
+}
+
+// Duration Length of time written as in time.ParseDuration, such as 250ms or 10s
+type Duration time.Duration
+
+// UnmarshalText Parses a duration such as 250ms
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText Duration such as 250ms
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// ToolNames Tools that tools.enabled can select
+var ToolNames = []string{"calculator", "system_info", "go_test_scaffold"}
+
+// ResourceGroups Resources, with their templates, that resources.enabled can select
+var ResourceGroups = []string{
+	"system_status",
+	"math_constants",
+	"physical_constants",
+	"formula_sheets",
+	"metrics",
+	"prompt_locales",
+	"prompt_previews",
+	"docs",
+}
+
+// logFormats Formats of the server log
+var logFormats = []string{"text", "json"}
+
+// DefaultConfig Configuration used for settings that are not given
+func DefaultConfig() Config {
+	return Config{
+		Server: ServerConfig{
+			Name:     "tutorial-mcp-server",
+			Version:  mcp.BuildVersion(),
+			PageSize: 50,
+		},
+		Transports: TransportsConfig{
+			Serve:   []Transport{TransportStdio},
+			Failure: FailureExit,
+			SSE: SSEConfig{
+				Address:           ":8080",
+				KeepAlive:         true,
+				KeepAliveInterval: Duration(10 * time.Second),
+			},
+			StreamableHTTP: StreamableHTTPConfig{
+				Address:   ":8081",
+				Stateless: true,
+			},
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Tools: ToolsConfig{
+			Calculator:     CalculatorConfig{Precision: 6},
+			GoTestScaffold: GoTestScaffoldConfig{MaxSourceBytes: 1 << 20},
+		},
+		Prompts: PromptsConfig{
+			ArgumentMaxLength: 10000,
+		},
+		Resources: ResourcesConfig{
+			MaxBytes:      1 << 20,
+			WatchDebounce: Duration(250 * time.Millisecond),
+		},
+	}
+}
+
+// Validate Every problem of the configuration, joined, with the key of the setting at fault
+func (c Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Name == "" {
+		add("server.name: must not be empty")
+	}
+	if c.Server.Version == "" {
+		add("server.version: must not be empty")
+	}
+	if c.Server.PageSize <= 0 {
+		add("server.page_size: must be positive, got %d", c.Server.PageSize)
+	}
+
+	if len(c.Transports.Serve) == 0 {
+		add("transports.serve: at least one transport is needed")
+	}
+	for i, transport := range c.Transports.Serve {
+		if _, err := ParseTransport(string(transport)); err != nil {
+			add("transports.serve: %v", err)
+		} else if slices.Contains(c.Transports.Serve[:i], transport) {
+			add("transports.serve: transport %s selected twice", transport)
+		}
+	}
+	if _, err := ParseFailurePolicy(string(c.Transports.Failure)); err != nil {
+		add("transports.failure: %v", err)
+	}
+	if err := validateAddress(c.Transports.SSE.Address); err != nil {
+		add("transports.sse.address: %v", err)
+	}
+	if c.Transports.SSE.KeepAliveInterval <= 0 {
+		add("transports.sse.keepalive_interval: must be positive, got %s", time.Duration(c.Transports.SSE.KeepAliveInterval))
+	}
+	if err := validateAddress(c.Transports.StreamableHTTP.Address); err != nil {
+		add("transports.streamable_http.address: %v", err)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		add("log.level: unknown level %q, expected debug, info, warn or error", c.Log.Level)
+	}
+	if !slices.Contains(logFormats, c.Log.Format) {
+		add("log.format: unknown format %q, expected text or json", c.Log.Format)
+	}
+
+	for _, err := range validateNames(c.Tools.Enabled, ToolNames, "tool") {
+		add("tools.enabled: %v", err)
+	}
+	if p := c.Tools.Calculator.Precision; p < 0 || p > 15 {
+		add("tools.calculator.precision: must be between 0 and 15, got %d", p)
+	}
+	if zone := c.Tools.SystemInfo.TimeZone; zone != "" {
+		if _, err := time.LoadLocation(zone); err != nil {
+			add("tools.system_info.time_zone: unknown time zone %q", zone)
+		}
+	}
+	if c.Tools.GoTestScaffold.MaxSourceBytes <= 0 {
+		add("tools.go_test_scaffold.max_source_bytes: must be positive, got %d", c.Tools.GoTestScaffold.MaxSourceBytes)
+	}
+
+	// Prompt names depend on the prompt files, so New checks that they exist
+	for _, err := range validateNames(c.Prompts.Enabled, nil, "prompt") {
+		add("prompts.enabled: %v", err)
+	}
+	if err := validateDir(c.Prompts.Dir); err != nil {
+		add("prompts.dir: %v", err)
+	}
+	if _, err := mcp.NewArgumentPolicy(c.Prompts.InjectionPolicy, false, 1, nil); err != nil {
+		add("prompts.injection_policy: %v", err)
+	}
+	if c.Prompts.ArgumentMaxLength <= 0 {
+		add("prompts.argument_max_length: must be positive, got %d", c.Prompts.ArgumentMaxLength)
+	}
+	for _, root := range c.Prompts.GitRoots {
+		if err := validateDir(root); err != nil {
+			add("prompts.git_roots: %v", err)
+		}
+	}
+
+	for _, err := range validateNames(c.Resources.Enabled, ResourceGroups, "resource group") {
+		add("resources.enabled: %v", err)
+	}
+	if err := validateDir(c.Resources.Dir); err != nil {
+		add("resources.dir: %v", err)
+	}
+	if c.Resources.MaxBytes <= 0 {
+		add("resources.max_bytes: must be positive, got %d", c.Resources.MaxBytes)
+	}
+	if c.Resources.WatchDebounce <= 0 {
+		add("resources.watch_debounce: must be positive, got %s", time.Duration(c.Resources.WatchDebounce))
+	}
+	if err := validateDir(c.Resources.DocsDir); err != nil {
+		add("resources.docs_dir: %v", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateAddress Checks a host:port listen address with a numeric port
+func validateAddress(address string) error {
+	_, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: expected host:port or :port", address)
+	}
+	if p, err := strconv.Atoi(portStr); err != nil || p < 0 || p > 65535 {
+		return fmt.Errorf("invalid port %q in address %q", portStr, address)
+	}
+	return nil
+}
+
+// validateDir Checks that a configured directory exists, when one is set
+func validateDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%q is not a directory", dir)
+	}
+	return nil
+}
+
+// validateNames Problems of a list of names: empty or repeated names, and names missing from known when it is given
+func validateNames(names, known []string, kind string) []error {
+	var errs []error
+	for i, name := range names {
+		switch {
+		case name == "":
+			errs = append(errs, fmt.Errorf("empty %s name", kind))
+		case known != nil && !slices.Contains(known, name):
+			errs = append(errs, fmt.Errorf("unknown %s %q", kind, name))
+		case slices.Contains(names[:i], name):
+			errs = append(errs, fmt.Errorf("%s %q listed twice", kind, name))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,401 @@
+package builder
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// setting Configuration key overridden by an environment variable and a command line flag
+type setting struct {
+	// key Dotted path of the setting in the config file
+	key  string
+	env  string
+	flag string
+	// separator Separator of list values, a comma when empty
+	separator string
+	usage     string
+	// apply Sets the value instead of key, for shorthands that set several keys
+	apply func(config *Config, value string) error
+}
+
+// settings Settings overridable from the environment and the command line, applied in this order
+var settings = []setting{
+	{key: "server.name", env: "SERVER_NAME", flag: "server-name", usage: "server name reported to clients"},
+	{key: "server.version", env: "SERVER_VERSION", flag: "server-version", usage: "server version reported to clients"},
+	{key: "server.page_size", env: "PAGE_SIZE", flag: "page-size", usage: "items per page of list results"},
+	{key: "transports.serve", env: "TRANSPORTS", flag: "transport", usage: "comma-separated transports to serve: stdio, sse, streamable_http"},
+	{key: "transports.failure", env: "TRANSPORT_FAILURE", flag: "transport-failure", usage: "what a failing transport does to the others: exit or isolate"},
+	{env: "PORT", flag: "port", usage: "port of both HTTP transports, overridden by their addresses", apply: applyPort},
+	{key: "transports.sse.address", env: "SSE_ADDRESS", flag: "sse-address", usage: "listen address of the sse transport"},
+	{key: "transports.sse.keepalive", env: "SSE_KEEPALIVE", flag: "sse-keepalive", usage: "send keepalive events on idle sse streams"},
+	{key: "transports.sse.keepalive_interval", env: "SSE_KEEPALIVE_INTERVAL", flag: "sse-keepalive-interval", usage: "time between sse keepalive events"},
+	{key: "transports.streamable_http.address", env: "STREAMABLE_HTTP_ADDRESS", flag: "streamable-http-address", usage: "listen address of the streamable_http transport"},
+	{key: "transports.streamable_http.stateless", env: "STREAMABLE_HTTP_STATELESS", flag: "streamable-http-stateless", usage: "serve streamable_http requests without sessions"},
+	{key: "log.level", env: "LOG_LEVEL", flag: "log-level", usage: "lowest level logged: debug, info, warn or error"},
+	{key: "log.format", env: "LOG_FORMAT", flag: "log-format", usage: "log line format: text or json"},
+	{key: "tools.enabled", env: "ENABLED_TOOLS", flag: "enabled-tools", usage: "comma-separated tools to serve, all when unset"},
+	{key: "tools.calculator.precision", env: "CALCULATOR_PRECISION", flag: "calculator-precision", usage: "decimal places of calculator results"},
+	{key: "tools.system_info.time_zone", env: "SYSTEM_INFO_TIME_ZONE", flag: "system-info-time-zone", usage: "time zone of system_info times, the host's when unset"},
+	{key: "tools.go_test_scaffold.max_source_bytes", env: "GO_TEST_SCAFFOLD_MAX_SOURCE_BYTES", flag: "go-test-scaffold-max-source-bytes", usage: "largest source accepted by go_test_scaffold"},
+	{key: "prompts.enabled", env: "ENABLED_PROMPTS", flag: "enabled-prompts", usage: "comma-separated prompts to serve, all when unset"},
+	{key: "prompts.dir", env: "PROMPTS_DIR", flag: "prompts-dir", usage: "directory of prompt files"},
+	{key: "prompts.variants", env: "PROMPT_VARIANTS", flag: "prompt-variants", usage: "versions served by unversioned prompt names, as name=version or name=version:weight,version:weight; sessions are assigned by a hash of their ID, so weights hold on average over many sessions"},
+	{key: "prompts.injection_policy", env: "PROMPT_INJECTION_POLICY", flag: "prompt-injection-policy", usage: "action on injection-like arguments: off, log, neutralize or reject"},
+	{key: "prompts.fence_arguments", env: "PROMPT_FENCE_ARGUMENTS", flag: "prompt-fence-arguments", usage: "wrap free-form arguments in <user-input> tags"},
+	{key: "prompts.argument_max_length", env: "PROMPT_ARGUMENT_MAX_LENGTH", flag: "prompt-argument-max-length", usage: "length limit of free-form arguments"},
+	{key: "prompts.git_roots", env: "GIT_ROOTS", flag: "git-roots", separator: string(os.PathListSeparator), usage: "repositories git_review may read, separated like PATH"},
+	{key: "resources.enabled", env: "ENABLED_RESOURCES", flag: "enabled-resources", usage: "comma-separated resource groups to serve, all when unset"},
+	{key: "resources.dir", env: "RESOURCE_DIR", flag: "resource-dir", usage: "directory served as workspace:/// resources"},
+	{key: "resources.max_bytes", env: "RESOURCE_MAX_BYTES", flag: "resource-max-bytes", usage: "largest file served from the resource dir"},
+	{key: "resources.include_hidden", env: "RESOURCE_INCLUDE_HIDDEN", flag: "resource-include-hidden", usage: "serve hidden files and directories of the resource dir, such as .git/ and .env"},
+	{key: "resources.watch_debounce", env: "RESOURCE_WATCH_DEBOUNCE", flag: "resource-watch-debounce", usage: "window in which resource dir changes are coalesced"},
+	{key: "resources.docs_dir", env: "DOCS_DIR", flag: "docs-dir", usage: "directory of markdown docs added to the embedded ones"},
+}
+
+// applyPort Listens with both HTTP transports on one port
+func applyPort(config *Config, value string) error {
+	p, err := strconv.Atoi(value)
+	if err != nil || p <= 0 || p > 65535 {
+		return errors.New("expected a port number")
+	}
+	config.Transports.SSE.Address = fmt.Sprintf(":%d", p)
+	config.Transports.StreamableHTTP.Address = fmt.Sprintf(":%d", p)
+	return nil
+}
+
+// set Parses a value given in the environment or on the command line into the configuration
+func (s setting) set(config *Config, value string) error {
+	if s.apply != nil {
+		return s.apply(config, value)
+	}
+	field, _ := configField(reflect.ValueOf(config).Elem(), s.key)
+	separator := s.separator
+	if separator == "" {
+		separator = ","
+	}
+	return parseValue(field, value, separator)
+}
+
+// applyEnv Applies the environment variables that are set, reporting every invalid one
+func applyEnv(config *Config) error {
+	var errs []error
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			if err := s.set(config, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s %q: %w", s.env, value, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// configField Field of a configuration struct at a dotted key
+func configField(v reflect.Value, key string) (reflect.Value, bool) {
+	for _, name := range strings.Split(key, ".") {
+		index, exists := fieldIndex(v.Type(), name)
+		if !exists {
+			return reflect.Value{}, false
+		}
+		v = v.Field(index)
+	}
+	return v, true
+}
+
+// fieldIndex Index of the struct field whose json name is name
+func fieldIndex(t reflect.Type, name string) (int, bool) {
+	for i := range t.NumField() {
+		if tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); tag == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// parseValue Parses text into a setting, splitting lists on separator
+func parseValue(target reflect.Value, value string, separator string) error {
+	if unmarshaler, ok := target.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("expected %s", describeType(target.Type()))
+		}
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("expected %s", describeType(target.Type()))
+		}
+		target.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected %s", describeType(target.Type()))
+		}
+		target.SetInt(n)
+	case reflect.Slice:
+		// An empty value selects nothing rather than falling back to the default
+		list := reflect.MakeSlice(target.Type(), 0, 0)
+		if value != "" {
+			for _, part := range strings.Split(value, separator) {
+				item := reflect.New(target.Type().Elem()).Elem()
+				if err := parseValue(item, strings.TrimSpace(part), separator); err != nil {
+					return err
+				}
+				list = reflect.Append(list, item)
+			}
+		}
+		target.Set(list)
+	default:
+		target.SetString(value)
+	}
+	return nil
+}
+
+// describeType Kind of value a setting expects, for error messages
+func describeType(t reflect.Type) string {
+	if t == reflect.TypeFor[Duration]() {
+		return "a duration such as 250ms"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int64:
+		return "an integer"
+	case reflect.Slice:
+		return "a list of strings"
+	default:
+		return "a string"
+	}
+}
+
+// configDecoders Decoders of the supported config file formats, by file extension
+var configDecoders = map[string]func(data []byte) (map[string]any, error){
+	".yaml": decodeYAML,
+	".yml":  decodeYAML,
+	".json": decodeJSON,
+	".toml": decodeTOML,
+}
+
+// decodeYAML Settings of a YAML config file
+func decodeYAML(data []byte) (map[string]any, error) {
+	var values map[string]any
+	err := yaml.Unmarshal(data, &values)
+	return values, err
+}
+
+// decodeJSON Settings of a JSON config file
+func decodeJSON(data []byte) (map[string]any, error) {
+	var values map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Numbers stay exact, so large integers are not rounded through float64
+	decoder.UseNumber()
+	err := decoder.Decode(&values)
+	return values, err
+}
+
+// decodeTOML Settings of a TOML config file
+func decodeTOML(data []byte) (map[string]any, error) {
+	var values map[string]any
+	err := toml.Unmarshal(data, &values)
+	return values, err
+}
+
+// readConfigFile Applies the settings of a YAML, JSON or TOML file, reporting every unknown key and invalid value
+func readConfigFile(path string, config *Config) error {
+	decode, supported := configDecoders[strings.ToLower(filepath.Ext(path))]
+	if !supported {
+		return fmt.Errorf("config file %s: unsupported format, expected .yaml, .yml, .json or .toml", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	values, err := decode(data)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	errs := decodeSettings(values, reflect.ValueOf(config).Elem(), "")
+	for i, err := range errs {
+		errs[i] = fmt.Errorf("%s: %w", path, err)
+	}
+	return errors.Join(errs...)
+}
+
+// decodeSettings Stores decoded settings in target, descending into sections so that every problem is reported with its key
+func decodeSettings(value any, target reflect.Value, key string) []error {
+	if target.Kind() == reflect.Struct {
+		// An empty section, such as "tools:" in YAML, keeps its defaults
+		if value == nil {
+			return nil
+		}
+		values, ok := value.(map[string]any)
+		if !ok {
+			return []error{fmt.Errorf("%s: expected a section of settings", key)}
+		}
+
+		var errs []error
+		keys := make([]string, 0, len(values))
+		for name := range values {
+			keys = append(keys, name)
+		}
+		sort.Strings(keys)
+		for _, name := range keys {
+			fieldKey := name
+			if key != "" {
+				fieldKey = key + "." + name
+			}
+			index, exists := fieldIndex(target.Type(), name)
+			if !exists {
+				errs = append(errs, fmt.Errorf("%s: unknown setting", fieldKey))
+				continue
+			}
+			errs = append(errs, decodeSettings(values[name], target.Field(index), fieldKey)...)
+		}
+		return errs
+	}
+
+	// Values go through JSON so that every format gets the same type checks
+	data, err := json.Marshal(value)
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", key, err)}
+	}
+	decoded := reflect.New(target.Type())
+	if value == nil || json.Unmarshal(data, decoded.Interface()) != nil {
+		return []error{fmt.Errorf("%s: expected %s, got %s", key, describeType(target.Type()), data)}
+	}
+	target.Set(decoded.Elem())
+	return nil
+}
+
+// flagValue Command line flag recording its values, which are applied after the config file and environment
+type flagValue struct {
+	setting setting
+	// order Index of the setting, which orders the assignments
+	order       int
+	isBool      bool
+	assignments *[]flagAssignment
+}
+
+// flagAssignment Value given to a flag
+type flagAssignment struct {
+	setting setting
+	order   int
+	value   string
+}
+
+// String Flags have no default of their own; DefaultConfig holds them
+func (f *flagValue) String() string {
+	return ""
+}
+
+// Set Records a value
+func (f *flagValue) Set(value string) error {
+	*f.assignments = append(*f.assignments, flagAssignment{setting: f.setting, order: f.order, value: value})
+	return nil
+}
+
+// IsBoolFlag Lets boolean settings be given as -name without a value
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// errUsage Command line error the flag package has already reported along with the usage
+var errUsage = errors.New("invalid command line")
+
+// Load Configuration of a command: the defaults, overridden by the config file, then the environment, then flags, reporting every problem at once.
+//
+// The config file is named by -config or CONFIG_FILE. Arguments after the
+// flags name the transports to serve. Commands that pass fixed transports
+// serve those whatever the configuration says, and take no arguments.
+func Load(name string, args []string, fixed ...Transport) (Config, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := flags.String("config", "", "YAML, JSON or TOML config file (CONFIG_FILE)")
+
+	var assignments []flagAssignment
+	for i, s := range settings {
+		if len(fixed) > 0 && s.key == "transports.serve" {
+			continue
+		}
+		field, _ := configField(reflect.ValueOf(&Config{}).Elem(), s.key)
+		isBool := s.key != "" && field.Kind() == reflect.Bool
+		flags.Var(&flagValue{setting: s, order: i, isBool: isBool, assignments: &assignments}, s.flag, fmt.Sprintf("%s (%s)", s.usage, s.env))
+	}
+	flags.Usage = func() {
+		if len(fixed) > 0 {
+			fmt.Fprintf(flags.Output(), "Usage: %s [flags]\n\n", name)
+		} else {
+			fmt.Fprintf(flags.Output(), "Usage: %s [flags] [stdio|sse|streamable_http ...]\n\n", name)
+		}
+		fmt.Fprintf(flags.Output(), "Settings come from the config file, then the environment variables in parentheses, then flags.\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return Config{}, err
+		}
+		return Config{}, fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	config := DefaultConfig()
+	var errs []error
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		errs = append(errs, readConfigFile(path, &config))
+	}
+
+	errs = append(errs, applyEnv(&config))
+
+	// Flags apply in the order of the settings, like the environment, so -port
+	// never overrides an address given anywhere on the command line
+	slices.SortStableFunc(assignments, func(a, b flagAssignment) int { return a.order - b.order })
+	transportFlag := ""
+	for _, a := range assignments {
+		if err := a.setting.set(&config, a.value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid -%s %q: %w", a.setting.flag, a.value, err))
+		}
+		if a.setting.key == "transports.serve" {
+			transportFlag = a.value
+		}
+	}
+
+	switch {
+	case len(fixed) > 0:
+		if flags.NArg() > 0 {
+			errs = append(errs, fmt.Errorf("unexpected arguments %s: %s always serves %s", strings.Join(flags.Args(), " "), name, fixed[0]))
+		}
+		config.Transports.Serve = fixed
+	case flags.NArg() > 0:
+		serve := make([]Transport, flags.NArg())
+		for i, arg := range flags.Args() {
+			serve[i] = Transport(arg)
+		}
+		if transportFlag != "" && !slices.Equal(config.Transports.Serve, serve) {
+			errs = append(errs, fmt.Errorf("transports given both as -transport %s and as arguments %s", transportFlag, strings.Join(flags.Args(), " ")))
+		}
+		config.Transports.Serve = serve
+	}
+
+	errs = append(errs, config.Validate())
+	return config, errors.Join(errs...)
+}
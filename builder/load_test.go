@@ -0,0 +1,172 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearEnv Empties every setting's environment variable, which Load treats as unset
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	for _, s := range settings {
+		t.Setenv(s.env, "")
+	}
+}
+
+// writeConfig Config file with the given name and content in a temporary directory
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeConfig(t, "server.yaml", `
+server:
+  name: from-file
+  page_size: 10
+log:
+  level: warn
+transports:
+  sse:
+    address: ":7000"
+`)
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		args       []string
+		serverName string
+		pageSize   int
+		logLevel   string
+		sse        string
+		streamable string
+	}{
+		{
+			name:       "file",
+			serverName: "from-file", pageSize: 10, logLevel: "warn", sse: ":7000", streamable: ":8081",
+		},
+		{
+			name:       "environment over file",
+			env:        map[string]string{"SERVER_NAME": "from-env", "PAGE_SIZE": "20"},
+			serverName: "from-env", pageSize: 20, logLevel: "warn", sse: ":7000", streamable: ":8081",
+		},
+		{
+			name:       "flags over environment",
+			env:        map[string]string{"SERVER_NAME": "from-env", "PAGE_SIZE": "20"},
+			args:       []string{"-server-name", "from-flag", "-log-level", "debug"},
+			serverName: "from-flag", pageSize: 20, logLevel: "debug", sse: ":7000", streamable: ":8081",
+		},
+		{
+			name:       "environment port over file address",
+			env:        map[string]string{"PORT": "9000"},
+			serverName: "from-file", pageSize: 10, logLevel: "warn", sse: ":9000", streamable: ":9000",
+		},
+		{
+			name:       "environment address over environment port",
+			env:        map[string]string{"PORT": "9000", "STREAMABLE_HTTP_ADDRESS": ":9001"},
+			serverName: "from-file", pageSize: 10, logLevel: "warn", sse: ":9000", streamable: ":9001",
+		},
+		{
+			name:       "flag address over a later port flag",
+			args:       []string{"-sse-address", ":9002", "-port", "9000"},
+			serverName: "from-file", pageSize: 10, logLevel: "warn", sse: ":9002", streamable: ":9000",
+		},
+		{
+			name:       "port flag over environment address",
+			env:        map[string]string{"SSE_ADDRESS": ":9003"},
+			args:       []string{"-port", "9000"},
+			serverName: "from-file", pageSize: 10, logLevel: "warn", sse: ":9000", streamable: ":9000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("CONFIG_FILE", file)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			config, err := Load("test", tt.args)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if config.Server.Name != tt.serverName {
+				t.Errorf("server.name = %q, want %q", config.Server.Name, tt.serverName)
+			}
+			if config.Server.PageSize != tt.pageSize {
+				t.Errorf("server.page_size = %d, want %d", config.Server.PageSize, tt.pageSize)
+			}
+			if config.Log.Level != tt.logLevel {
+				t.Errorf("log.level = %q, want %q", config.Log.Level, tt.logLevel)
+			}
+			if config.Transports.SSE.Address != tt.sse {
+				t.Errorf("transports.sse.address = %q, want %q", config.Transports.SSE.Address, tt.sse)
+			}
+			if config.Transports.StreamableHTTP.Address != tt.streamable {
+				t.Errorf("transports.streamable_http.address = %q, want %q", config.Transports.StreamableHTTP.Address, tt.streamable)
+			}
+		})
+	}
+}
+
+func TestLoadFileFormats(t *testing.T) {
+	files := map[string]string{
+		"server.yaml": "server:\n  name: tutor\n  page_size: 5\n",
+		"server.json": `{"server": {"name": "tutor", "page_size": 5}}`,
+		"server.toml": "[server]\nname = \"tutor\"\npage_size = 5\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			config, err := Load("test", []string{"-config", writeConfig(t, name, content)})
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if config.Server.Name != "tutor" || config.Server.PageSize != 5 {
+				t.Errorf("server = %+v, want name tutor and page_size 5", config.Server)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	clearEnv(t)
+	file := writeConfig(t, "server.yaml", `
+server:
+  page_size: many
+  colour: blue
+transports:
+  sse:
+    keepalive: sometimes
+`)
+	t.Setenv("PORT", "http")
+	t.Setenv("CALCULATOR_PRECISION", "high")
+
+	_, err := Load("test", []string{"-config", file, "-log-level", "loud", "-resource-watch-debounce", "soon"})
+	if err == nil {
+		t.Fatal("Load accepted an invalid configuration")
+	}
+
+	// Every problem is reported at once, from the file, the environment, flags and validation
+	for _, want := range []string{
+		"server.colour: unknown setting",
+		"server.page_size: expected an integer",
+		"transports.sse.keepalive: expected true or false",
+		`invalid PORT "http": expected a port number`,
+		`invalid CALCULATOR_PRECISION "high": expected an integer`,
+		`invalid -resource-watch-debounce "soon": expected a duration such as 250ms`,
+		"log.level",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/sync/errgroup"
+)
+
+// Transport Protocol the server is served over
+type Transport string
+
+// Supported transports
+const (
+	// TransportStdio JSON-RPC over stdin and stdout, for a single client that starts the server
+	TransportStdio Transport = "stdio"
+	// TransportSSE Server-Sent Events with per-client sessions, on port 8080 by default
+	TransportSSE Transport = "sse"
+	// TransportStreamableHTTP Streamable HTTP at /mcp, stateless and on port 8081 by default
+	TransportStreamableHTTP Transport = "streamable_http"
+)
+
+// Transports Every supported transport
+var Transports = []Transport{TransportStdio, TransportSSE, TransportStreamableHTTP}
+
+// shutdownTimeout Time HTTP transports get to finish open requests once the server stops
+const shutdownTimeout = 5 * time.Second
+
+// ParseTransport Transport with the given name
+func ParseTransport(name string) (Transport, error) {
+	for _, transport := range Transports {
+		if string(transport) == name {
+			return transport, nil
+		}
+	}
+	names := make([]string, len(Transports))
+	for i, transport := range Transports {
+		names[i] = string(transport)
+	}
+	return "", fmt.Errorf("unknown transport %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// FailurePolicy What happens to the other transports when one of them fails
+type FailurePolicy string
+
+// Supported failure policies
+const (
+	// FailureExit Stops every transport and exits with the error, the default
+	FailureExit FailurePolicy = "exit"
+	// FailureIsolate Logs the error and keeps serving the other transports
+	FailureIsolate FailurePolicy = "isolate"
+)
+
+// ParseFailurePolicy Failure policy with the given name
+func ParseFailurePolicy(name string) (FailurePolicy, error) {
+	switch policy := FailurePolicy(name); policy {
+	case FailureExit, FailureIsolate:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown transport failure policy %q, expected %s or %s", name, FailureExit, FailureIsolate)
+}
+
+// NewLogger Logger with the configured level and format, writing to stderr when stdio is among the transports, since stdout carries its protocol, and to stdout otherwise
+func NewLogger(config LogConfig, transports ...Transport) *slog.Logger {
+	output := os.Stdout
+	if slices.Contains(transports, TransportStdio) {
+		output = os.Stderr
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(config.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+	options := &slog.HandlerOptions{Level: level}
+	if config.Format == "json" {
+		return slog.New(slog.NewJSONHandler(output, options))
+	}
+	return slog.New(slog.NewTextHandler(output, options))
+}
+
+// Main Loads the configuration from the command line, config file and environment and serves the server until interrupted, exiting on failure.
+//
+// Commands that always serve the same transports pass them; the others take
+// them from the configuration.
+func Main(transports ...Transport) {
+	config, err := Load(filepath.Base(os.Args[0]), os.Args[1:], transports...)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(0)
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "Invalid configuration:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(os.Stderr, "  %s\n", line)
+		}
+		os.Exit(1)
+	}
+
+	logger := NewLogger(config.Log, config.Transports.Serve...)
+
+	ctx, stop := signal.NotifyContext(
+		context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+	)
+	defer stop()
+
+	s, err := New(config, logger)
+	if err != nil {
+		logger.Error("Failed to build server", "error", err)
+		os.Exit(1)
+	}
+
+	if err := s.Serve(ctx, config.Transports.Serve...); err != nil {
+		logger.Error("Server error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// Serve Starts the background watchers and serves the server on every transport at once until ctx is cancelled.
+//
+// All transports share the one MCPServer, so sessions, metrics and prompt
+// reloads are the same whichever transport a client uses. The HTTP transports
+// listen on their configured addresses, sharing one listener when the
+// addresses are the same. A transport that fails stops the others under
+// FailureExit and is dropped alone under FailureIsolate; a stdio client
+// closing stdin is not a failure and leaves the other transports running.
+func (s *Server) Serve(ctx context.Context, transports ...Transport) error {
+	if len(transports) == 0 {
+		return errors.New("no transport selected")
+	}
+
+	addresses := map[string][]Transport{}
+	var addressOrder []string
+	serveStdio := false
+	for i, transport := range transports {
+		if slices.Contains(transports[:i], transport) {
+			return fmt.Errorf("transport %s selected twice", transport)
+		}
+		switch transport {
+		case TransportStdio:
+			serveStdio = true
+		case TransportSSE, TransportStreamableHTTP:
+			address := s.config.Transports.SSE.Address
+			if transport == TransportStreamableHTTP {
+				address = s.config.Transports.StreamableHTTP.Address
+			}
+			if _, exists := addresses[address]; !exists {
+				addressOrder = append(addressOrder, address)
+			}
+			addresses[address] = append(addresses[address], transport)
+		default:
+			return fmt.Errorf("unknown transport %q", transport)
+		}
+	}
+
+	watchCtx, stopWatchers := context.WithCancel(ctx)
+	defer stopWatchers()
+	for _, watch := range s.watchers {
+		go watch(watchCtx)
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	var failed atomic.Int32
+	units := 0
+	run := func(name string, serve func(ctx context.Context) error) {
+		units++
+		group.Go(func() error {
+			err := serve(groupCtx)
+			if err == nil {
+				return nil
+			}
+			if s.config.Transports.Failure == FailureIsolate {
+				failed.Add(1)
+				s.logger.Error("Transport failed, isolating it", "transport", name, "error", err)
+				return nil
+			}
+			return fmt.Errorf("%s: %w", name, err)
+		})
+	}
+
+	if serveStdio {
+		run(string(TransportStdio), s.serveStdio)
+	}
+	for _, address := range addressOrder {
+		names := make([]string, len(addresses[address]))
+		for i, transport := range addresses[address] {
+			names[i] = string(transport)
+		}
+		run(strings.Join(names, "+"), func(ctx context.Context) error {
+			return s.serveHTTP(ctx, address, addresses[address])
+		})
+	}
+	s.logger.Info("Tutorial MCP Server started", "name", s.config.Server.Name, "version", s.config.Server.Version, "transports", transports, "failure", s.config.Transports.Failure)
+
+	err := group.Wait()
+	s.logger.Info("Tutorial MCP Server stopped")
+	if err != nil {
+		return err
+	}
+	if int(failed.Load()) == units {
+		return errors.New("every transport failed")
+	}
+	return nil
+}
+
+// serveStdio Serves the stdio transport until ctx is cancelled or the client closes stdin
+func (s *Server) serveStdio(ctx context.Context) error {
+	stdioServer := server.NewStdioServer(s.MCPServer)
+	s.logger.Info("Transport started", "transport", TransportStdio)
+	err := stdioServer.Listen(ctx, os.Stdin, s.metrics.CountWriter(os.Stdout, string(TransportStdio)))
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// serveHTTP Serves the HTTP transports sharing an address, next to /metrics, until ctx is cancelled
+func (s *Server) serveHTTP(ctx context.Context, address string, transports []Transport) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.metrics.Handler())
+	httpServer := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	// Open streams are closed first, or Shutdown would wait for them until it times out
+	var closeSessions []func(ctx context.Context)
+	for _, transport := range transports {
+		switch transport {
+		case TransportSSE:
+			sseServer := server.NewSSEServer(
+				s.MCPServer,
+				server.WithKeepAlive(s.config.Transports.SSE.KeepAlive),
+				server.WithKeepAliveInterval(time.Duration(s.config.Transports.SSE.KeepAliveInterval)),
+			)
+			mux.Handle("/", s.metrics.CountHandler(sseServer, string(TransportSSE)))
+			closeSessions = append(closeSessions, func(context.Context) { sseServer.CloseSessions() })
+		case TransportStreamableHTTP:
+			streamableServer := server.NewStreamableHTTPServer(
+				s.MCPServer,
+				server.WithStateLess(s.config.Transports.StreamableHTTP.Stateless),
+			)
+			mux.Handle("/mcp", s.metrics.CountHandler(streamableServer, string(TransportStreamableHTTP)))
+			closeSessions = append(closeSessions, streamableServer.CloseSessions)
+		}
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	for _, transport := range transports {
+		s.logger.Info("Transport started", "transport", transport, "address", address)
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		for _, closeSession := range closeSessions {
+			closeSession(shutdownCtx)
+		}
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			s.logger.Warn("Failed to shut down cleanly", "address", address, "error", err)
+		}
+		return nil
+	case err := <-errChan:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
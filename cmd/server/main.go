@@ -0,0 +1,14 @@
+// Command server serves the tutorial MCP server over the transports given as arguments, by -transport
+// or in the configuration, all at once against the same server:
+//
+//	server [flags] [stdio|sse|streamable_http ...]
+//	server -config server.yaml
+//
+// Run server -h for every flag and the environment variable it overrides.
+package main
+
+import "tutorial/builder"
+
+func main() {
+	builder.Main()
+}
@@ -0,0 +1,9 @@
+// Package tutorial embeds the project documentation so the server can publish it as resources.
+package tutorial
+
+import "embed"
+
+// Docs Project markdown documentation embedded into the server binaries
+//
+//go:embed README.md ARCHITECTURE.md MCP.md
+var Docs embed.FS
@@ -1,11 +1,21 @@
 module tutorial
 
-go 1.24.3
+go 1.25.5
 
-require github.com/mark3labs/mcp-go v0.32.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/mark3labs/mcp-go v0.58.0
+	github.com/yosida95/uritemplate/v3 v3.0.2
+	golang.org/x/sync v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
+	github.com/google/jsonschema-go v0.4.2 // indirect
 	github.com/google/uuid v1.6.0 // indirect
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2 // indirect
 	github.com/spf13/cast v1.7.1 // indirect
-	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
 )
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Argument formats parsed before rendering
+const (
+	// formatDiff A unified diff
+	formatDiff = "diff"
+	// formatStackTrace An error message or stack trace
+	formatStackTrace = "stacktrace"
+	// formatGoSource A Go source file
+	formatGoSource = "go"
+)
+
+// normalize Canonical value of the argument, or a description of why the input is invalid.
+//
+// Surrounding whitespace is dropped and an empty value falls back to the
+// default. Synonyms and differently cased or separated spellings of a curated
+// value resolve to that value; strict arguments accept nothing else.
+func (a promptArgument) normalize(input string) (string, error) {
+	value := strings.TrimSpace(input)
+	if a.Format == formatDiff {
+		// Leading and trailing context lines of a diff start with significant spaces
+		value = strings.Trim(input, "\r\n")
+	}
+	if value == "" {
+		if a.Required {
+			return "", fmt.Errorf("%s is required", a.Name)
+		}
+		return a.Default, nil
+	}
+
+	if a.MaxLength > 0 && utf8.RuneCountInString(value) > a.MaxLength {
+		return "", fmt.Errorf("%s must be at most %d characters", a.Name, a.MaxLength)
+	}
+
+	for synonym, canonical := range a.Synonyms {
+		if slug(synonym) == slug(value) {
+			value = canonical
+			break
+		}
+	}
+
+	for _, allowed := range a.Values {
+		if slug(allowed) == slug(value) {
+			return allowed, nil
+		}
+	}
+
+	if a.Strict {
+		return "", fmt.Errorf("%s must be one of %s, got %q", a.Name, strings.Join(a.Values, ", "), input)
+	}
+	return value, nil
+}
+
+// check Validates the declaration itself, so that defaults and synonyms cannot produce rejected values
+func (a promptArgument) check() error {
+	if a.MaxLength < 0 {
+		return fmt.Errorf("argument %q has a negative max_length", a.Name)
+	}
+	if a.Strict && len(a.Values) == 0 {
+		return fmt.Errorf("argument %q is strict but lists no values", a.Name)
+	}
+	switch a.Format {
+	case "", formatDiff, formatStackTrace, formatGoSource:
+	default:
+		return fmt.Errorf("argument %q has unknown format %q, expected %s, %s or %s", a.Name, a.Format, formatDiff, formatStackTrace, formatGoSource)
+	}
+	if a.Required && a.Default != "" {
+		return fmt.Errorf("argument %q is required and therefore cannot have a default", a.Name)
+	}
+
+	for _, synonym := range sortedKeys(a.Synonyms) {
+		if _, err := a.normalize(a.Synonyms[synonym]); err != nil {
+			return fmt.Errorf("argument %q synonym %q: %w", a.Name, synonym, err)
+		}
+	}
+	if a.Default != "" {
+		if _, err := a.normalize(a.Default); err != nil {
+			return fmt.Errorf("argument %q default: %w", a.Name, err)
+		}
+	}
+	return nil
+}
+
+// bindArguments Normalizes the request arguments against the declarations, rejecting undeclared ones
+func bindArguments(prompt string, declared []promptArgument, input map[string]string) (map[string]string, error) {
+	var problems []string
+
+	known := make(map[string]bool, len(declared))
+	args := make(map[string]string, len(declared))
+	for _, argument := range declared {
+		known[argument.Name] = true
+		value, err := argument.normalize(input[argument.Name])
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		args[argument.Name] = value
+	}
+
+	var unknown []string
+	for name := range input {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("unknown argument %q", name))
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("prompt %s: %s: %w", prompt, strings.Join(problems, "; "), mcp.ErrInvalidParams)
+	}
+	return args, nil
+}
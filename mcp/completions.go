@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxCompletionValues Upper bound on values returned in a single completion response
+const maxCompletionValues = 100
+
+// CompletionFunc Returns candidate values for an argument given its partial value and the arguments resolved so far
+type CompletionFunc func(ctx context.Context, value string, resolved map[string]string) []string
+
+// Completions Completion provider dispatching completion/complete requests to per-argument completion functions
+type Completions struct {
+	mu        sync.RWMutex
+	prompts   map[string]map[string]CompletionFunc
+	resources map[string]map[string]CompletionFunc
+}
+
+// NewCompletions Creates an empty completion provider
+func NewCompletions() *Completions {
+	return &Completions{
+		prompts:   make(map[string]map[string]CompletionFunc),
+		resources: make(map[string]map[string]CompletionFunc),
+	}
+}
+
+// AddPromptArgument Registers a completion function for an argument of a prompt
+func (c *Completions) AddPromptArgument(prompt, argument string, fn CompletionFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.prompts[prompt] == nil {
+		c.prompts[prompt] = make(map[string]CompletionFunc)
+	}
+	c.prompts[prompt][argument] = fn
+}
+
+// RemovePrompt Drops the completion functions of every argument of a prompt
+func (c *Completions) RemovePrompt(prompt string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.prompts, prompt)
+}
+
+// promptArgument Completion function registered for an argument of a prompt, or nil
+func (c *Completions) promptArgument(prompt, argument string) CompletionFunc {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.prompts[prompt][argument]
+}
+
+// AddResourceArgument Registers a completion function for a variable of a resource template
+func (c *Completions) AddResourceArgument(uriTemplate, argument string, fn CompletionFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resources[uriTemplate] == nil {
+		c.resources[uriTemplate] = make(map[string]CompletionFunc)
+	}
+	c.resources[uriTemplate][argument] = fn
+}
+
+// RemoveResource Drops the completion functions of every variable of a resource template
+func (c *Completions) RemoveResource(uriTemplate string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.resources, uriTemplate)
+}
+
+// CompletePromptArgument Implements server.PromptCompletionProvider
+func (c *Completions) CompletePromptArgument(ctx context.Context, promptName string, argument mcp.CompleteArgument, context mcp.CompleteContext) (*mcp.Completion, error) {
+	c.mu.RLock()
+	fn := c.prompts[promptName][argument.Name]
+	c.mu.RUnlock()
+
+	return complete(ctx, fn, argument, context), nil
+}
+
+// CompleteResourceArgument Implements server.ResourceCompletionProvider
+func (c *Completions) CompleteResourceArgument(ctx context.Context, uri string, argument mcp.CompleteArgument, context mcp.CompleteContext) (*mcp.Completion, error) {
+	c.mu.RLock()
+	fn := c.resources[uri][argument.Name]
+	c.mu.RUnlock()
+
+	return complete(ctx, fn, argument, context), nil
+}
+
+// complete Runs a completion function and caps the result at the protocol limit
+func complete(ctx context.Context, fn CompletionFunc, argument mcp.CompleteArgument, resolved mcp.CompleteContext) *mcp.Completion {
+	if fn == nil {
+		return &mcp.Completion{Values: []string{}}
+	}
+
+	values := fn(ctx, argument.Value, resolved.Arguments)
+	if values == nil {
+		values = []string{}
+	}
+
+	completion := &mcp.Completion{
+		Values: values,
+		Total:  len(values),
+	}
+	if len(values) > maxCompletionValues {
+		completion.Values = values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	return completion
+}
+
+// PrefixCompletion Completion function suggesting the given values that start with the typed prefix, case-insensitively
+func PrefixCompletion(values ...string) CompletionFunc {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+
+	return func(ctx context.Context, value string, resolved map[string]string) []string {
+		return filterPrefix(sorted, value)
+	}
+}
+
+// filterPrefix Values starting with prefix, ignoring case
+func filterPrefix(values []string, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	matches := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.HasPrefix(strings.ToLower(v), prefix) {
+			matches = append(matches, v)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,406 @@
+package mcp
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Limits applied when attaching a diff to a prompt; anything beyond them is summarized with a marker
+const (
+	maxDiffFiles     = 50
+	maxDiffFileLines = 500
+	maxDiffLines     = 3000
+)
+
+// diffMIMEType MIME type of attached per-file diffs
+const diffMIMEType = "text/x-diff"
+
+// diffLanguages Language names by file extension, used to label changed files
+var diffLanguages = map[string]string{
+	".go":    "Go",
+	".py":    "Python",
+	".js":    "JavaScript",
+	".mjs":   "JavaScript",
+	".cjs":   "JavaScript",
+	".jsx":   "JavaScript (React)",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript (React)",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".scala": "Scala",
+	".c":     "C",
+	".h":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".rs":    "Rust",
+	".rb":    "Ruby",
+	".php":   "PHP",
+	".swift": "Swift",
+	".sh":    "Shell",
+	".bash":  "Shell",
+	".sql":   "SQL",
+	".html":  "HTML",
+	".css":   "CSS",
+	".scss":  "SCSS",
+	".md":    "Markdown",
+	".json":  "JSON",
+	".yaml":  "YAML",
+	".yml":   "YAML",
+	".toml":  "TOML",
+	".proto": "Protocol Buffers",
+}
+
+// diffFileNames Language names of well-known files without a telling extension
+var diffFileNames = map[string]string{
+	"Dockerfile": "Dockerfile",
+	"Makefile":   "Makefile",
+	"go.mod":     "Go module",
+}
+
+// diffHunk A hunk of a unified diff
+type diffHunk struct {
+	header   string
+	oldStart int
+	oldLines int
+	newStart int
+	newLines int
+	lines    []string
+}
+
+// diffFile The hunks of one file in a unified diff
+type diffFile struct {
+	oldPath string
+	newPath string
+	status  string
+	binary  bool
+	hunks   []diffHunk
+}
+
+// path Path of the file after the change, or before it for deletions
+func (f *diffFile) path() string {
+	if f.newPath != "" {
+		return f.newPath
+	}
+	return f.oldPath
+}
+
+// language Language detected from the file name
+func (f *diffFile) language() string {
+	name := path.Base(f.path())
+	if language, ok := diffFileNames[name]; ok {
+		return language
+	}
+	if language, ok := diffLanguages[strings.ToLower(path.Ext(name))]; ok {
+		return language
+	}
+	return "text"
+}
+
+// changedLines Line ranges added in the new file and the number of removed lines
+func (f *diffFile) changedLines() ([]string, int) {
+	var added []int
+	removed := 0
+	for _, hunk := range f.hunks {
+		line := hunk.newStart
+		for _, l := range hunk.lines {
+			switch {
+			case strings.HasPrefix(l, "+"):
+				added = append(added, line)
+				line++
+			case strings.HasPrefix(l, "-"):
+				removed++
+			case strings.HasPrefix(l, `\`):
+			default:
+				line++
+			}
+		}
+	}
+	return lineRanges(added), removed
+}
+
+// lineRanges Compresses ascending line numbers into ranges like "10-14"
+func lineRanges(lines []int) []string {
+	var ranges []string
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(lines[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		}
+		i = j + 1
+	}
+	return ranges
+}
+
+// parseUnifiedDiff Splits a unified diff, as produced by git diff or diff -u, into per-file hunks
+func parseUnifiedDiff(text string) ([]*diffFile, error) {
+	var files []*diffFile
+	var current *diffFile
+	var hunk *diffHunk
+	oldRemaining, newRemaining := 0, 0
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(text)+1)
+	number := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		number++
+
+		// Inside a hunk the header counts say how many lines belong to it
+		if hunk != nil && (oldRemaining > 0 || newRemaining > 0) {
+			switch {
+			case line == "" || strings.HasPrefix(line, " "):
+				oldRemaining--
+				newRemaining--
+			case strings.HasPrefix(line, "-"):
+				oldRemaining--
+			case strings.HasPrefix(line, "+"):
+				newRemaining--
+			case strings.HasPrefix(line, `\`):
+			default:
+				return nil, fmt.Errorf("line %d: hunk ended early, expected %d more old and %d more new lines", number, max(oldRemaining, 0), max(newRemaining, 0))
+			}
+			hunk.lines = append(hunk.lines, line)
+			continue
+		}
+		if hunk != nil && strings.HasPrefix(line, `\`) {
+			hunk.lines = append(hunk.lines, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current = &diffFile{status: "modified"}
+			files = append(files, current)
+			hunk = nil
+			if a, b, ok := strings.Cut(strings.TrimPrefix(line, "diff --git "), " b/"); ok {
+				current.oldPath = strings.TrimPrefix(a, "a/")
+				current.newPath = b
+			}
+		case strings.HasPrefix(line, "--- "):
+			if current == nil || len(current.hunks) > 0 {
+				current = &diffFile{status: "modified"}
+				files = append(files, current)
+			}
+			hunk = nil
+			if name := diffPath(line[4:], "a/"); name == "" {
+				current.status = "added"
+				current.oldPath = ""
+			} else {
+				current.oldPath = name
+			}
+		case strings.HasPrefix(line, "+++ ") && current != nil:
+			if name := diffPath(line[4:], "b/"); name == "" {
+				current.status = "deleted"
+				current.newPath = ""
+			} else {
+				current.newPath = name
+			}
+		case strings.HasPrefix(line, "@@ ") && current != nil:
+			h, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", number, err)
+			}
+			current.hunks = append(current.hunks, h)
+			hunk = &current.hunks[len(current.hunks)-1]
+			oldRemaining, newRemaining = h.oldLines, h.newLines
+		case current != nil && strings.HasPrefix(line, "new file mode"):
+			current.status = "added"
+		case current != nil && strings.HasPrefix(line, "deleted file mode"):
+			current.status = "deleted"
+		case current != nil && strings.HasPrefix(line, "rename from "):
+			current.status = "renamed"
+			current.oldPath = strings.TrimPrefix(line, "rename from ")
+		case current != nil && strings.HasPrefix(line, "rename to "):
+			current.newPath = strings.TrimPrefix(line, "rename to ")
+		case current != nil && strings.HasPrefix(line, "Binary files "):
+			current.binary = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no file headers found, expected a unified diff such as the output of git diff")
+	}
+	for _, file := range files {
+		if file.status == "deleted" {
+			file.newPath = ""
+		}
+		if file.path() == "" {
+			return nil, fmt.Errorf("diff contains a file without a path")
+		}
+	}
+	return files, nil
+}
+
+// diffPath File path from a ---/+++ header, without the git prefix and timestamp; empty for /dev/null
+func diffPath(header, prefix string) string {
+	name, _, _ := strings.Cut(header, "\t")
+	name = strings.TrimSpace(name)
+	if name == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(name, prefix)
+}
+
+// parseHunkHeader Parses "@@ -oldStart,oldLines +newStart,newLines @@"
+func parseHunkHeader(line string) (diffHunk, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || !strings.HasPrefix(fields[3], "@@") {
+		return diffHunk{}, fmt.Errorf("malformed hunk header %q", line)
+	}
+
+	oldStart, oldLines, err := parseHunkRange(fields[1], "-")
+	if err != nil {
+		return diffHunk{}, fmt.Errorf("malformed hunk header %q: %w", line, err)
+	}
+	newStart, newLines, err := parseHunkRange(fields[2], "+")
+	if err != nil {
+		return diffHunk{}, fmt.Errorf("malformed hunk header %q: %w", line, err)
+	}
+
+	return diffHunk{
+		header:   line,
+		oldStart: oldStart,
+		oldLines: oldLines,
+		newStart: newStart,
+		newLines: newLines,
+	}, nil
+}
+
+// parseHunkRange Parses "-start,count" or "+start", where the count defaults to one
+func parseHunkRange(field, sign string) (int, int, error) {
+	if !strings.HasPrefix(field, sign) {
+		return 0, 0, fmt.Errorf("range %q does not start with %s", field, sign)
+	}
+	startText, countText, hasCount := strings.Cut(field[1:], ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q", field)
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q", field)
+		}
+	}
+	return start, count, nil
+}
+
+// diffAttachment Summary and per-file embedded resources of a parsed diff, within the attachment limits
+func diffAttachment(files []*diffFile) (string, []mcp.PromptMessage) {
+	var summary strings.Builder
+	var messages []mcp.PromptMessage
+	budget := maxDiffLines
+
+	for i, file := range files {
+		if i == maxDiffFiles {
+			fmt.Fprintf(&summary, "- [truncated: %d more changed files omitted]\n", len(files)-maxDiffFiles)
+			break
+		}
+
+		ranges, removed := file.changedLines()
+		fmt.Fprintf(&summary, "- %s (%s, %s)", file.path(), file.language(), file.status)
+		if file.status == "renamed" {
+			fmt.Fprintf(&summary, " from %s", file.oldPath)
+		}
+
+		switch {
+		case file.binary:
+			summary.WriteString(": binary file, not attached\n")
+			continue
+		case len(ranges) > 0:
+			fmt.Fprintf(&summary, ": added or changed lines %s", strings.Join(ranges, ", "))
+			if removed > 0 {
+				fmt.Fprintf(&summary, ", %s removed", pluralLines(removed))
+			}
+		case removed > 0:
+			fmt.Fprintf(&summary, ": %s removed", pluralLines(removed))
+		}
+
+		if budget <= 0 {
+			summary.WriteString(" [truncated: diff not attached, size limit reached]\n")
+			continue
+		}
+
+		text, used, truncated := renderDiffFile(file, min(budget, maxDiffFileLines))
+		budget -= used
+		if truncated {
+			summary.WriteString(" [truncated]")
+		}
+		summary.WriteString("\n")
+
+		messages = append(messages, mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+			Meta: map[string]any{
+				"path":      file.path(),
+				"language":  file.language(),
+				"status":    file.status,
+				"truncated": truncated,
+			},
+			URI:      "diff:///" + strings.TrimPrefix(FileURI(file.path()), workspaceURIPrefix),
+			MIMEType: diffMIMEType,
+			Text:     text,
+		})))
+	}
+
+	return strings.TrimRight(summary.String(), "\n"), messages
+}
+
+// pluralLines Line count with the noun in the right number
+func pluralLines(n int) string {
+	if n == 1 {
+		return "1 line"
+	}
+	return fmt.Sprintf("%d lines", n)
+}
+
+// renderDiffFile Unified diff text of one file limited to the given number of hunk lines
+func renderDiffFile(file *diffFile, limit int) (string, int, bool) {
+	var b strings.Builder
+	oldPath, newPath := "/dev/null", "/dev/null"
+	if file.oldPath != "" {
+		oldPath = "a/" + file.oldPath
+	}
+	if file.newPath != "" {
+		newPath = "b/" + file.newPath
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldPath, newPath)
+
+	used := 0
+	for i, hunk := range file.hunks {
+		if used+len(hunk.lines) > limit && used > 0 {
+			omitted := 0
+			for _, rest := range file.hunks[i:] {
+				omitted += len(rest.lines)
+			}
+			fmt.Fprintf(&b, "[truncated: %d more hunks with %d lines omitted]\n", len(file.hunks)-i, omitted)
+			return b.String(), used, true
+		}
+
+		b.WriteString(hunk.header)
+		b.WriteString("\n")
+		for j, line := range hunk.lines {
+			if used == limit {
+				fmt.Fprintf(&b, "[truncated: %d more lines of this hunk omitted]\n", len(hunk.lines)-j)
+				return b.String(), used, true
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+			used++
+		}
+	}
+	return b.String(), used, false
+}
@@ -0,0 +1,285 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		paths   []string
+		status  []string
+		binary  []bool
+		added   []string
+		removed int
+	}{
+		{
+			name: "modified file",
+			text: "diff --git a/main.go b/main.go\n" +
+				"index 1111111..2222222 100644\n" +
+				"--- a/main.go\n" +
+				"+++ b/main.go\n" +
+				"@@ -10,3 +10,4 @@ func main() {\n" +
+				" \tx := 1\n" +
+				"-\ty := 2\n" +
+				"+\ty := 3\n" +
+				"+\tz := 4\n" +
+				" \treturn\n",
+			paths:   []string{"main.go"},
+			status:  []string{"modified"},
+			binary:  []bool{false},
+			added:   []string{"11-12"},
+			removed: 1,
+		},
+		{
+			name: "rename with changes",
+			text: "diff --git a/old/name.py b/new/name.py\n" +
+				"similarity index 90%\n" +
+				"rename from old/name.py\n" +
+				"rename to new/name.py\n" +
+				"--- a/old/name.py\n" +
+				"+++ b/new/name.py\n" +
+				"@@ -1 +1 @@\n" +
+				"-print('a')\n" +
+				"+print('b')\n",
+			paths:   []string{"new/name.py"},
+			status:  []string{"renamed"},
+			binary:  []bool{false},
+			added:   []string{"1"},
+			removed: 1,
+		},
+		{
+			name: "pure rename",
+			text: "diff --git a/a.txt b/b.txt\n" +
+				"similarity index 100%\n" +
+				"rename from a.txt\n" +
+				"rename to b.txt\n",
+			paths:  []string{"b.txt"},
+			status: []string{"renamed"},
+			binary: []bool{false},
+		},
+		{
+			name: "binary file",
+			text: "diff --git a/logo.png b/logo.png\n" +
+				"new file mode 100644\n" +
+				"index 0000000..3333333\n" +
+				"Binary files /dev/null and b/logo.png differ\n",
+			paths:  []string{"logo.png"},
+			status: []string{"added"},
+			binary: []bool{true},
+		},
+		{
+			name: "no newline at end of file",
+			text: "--- a/README.md\n" +
+				"+++ b/README.md\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				" # Title\n" +
+				"-old\n" +
+				"\\ No newline at end of file\n" +
+				"+new\n" +
+				"\\ No newline at end of file\n",
+			paths:   []string{"README.md"},
+			status:  []string{"modified"},
+			binary:  []bool{false},
+			added:   []string{"2"},
+			removed: 1,
+		},
+		{
+			name: "added and deleted files",
+			text: "--- /dev/null\n" +
+				"+++ b/new.go\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+package new\n" +
+				"+\n" +
+				"--- a/gone.go\t2024-01-01 00:00:00\n" +
+				"+++ /dev/null\t2024-01-01 00:00:00\n" +
+				"@@ -1 +0,0 @@\n" +
+				"-package gone\n",
+			paths:  []string{"new.go", "gone.go"},
+			status: []string{"added", "deleted"},
+			binary: []bool{false, false},
+			added:  []string{"1-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := parseUnifiedDiff(tt.text)
+			if err != nil {
+				t.Fatalf("parseUnifiedDiff failed: %v", err)
+			}
+			if len(files) != len(tt.paths) {
+				t.Fatalf("parsed %d files, want %d", len(files), len(tt.paths))
+			}
+			for i, file := range files {
+				if file.path() != tt.paths[i] {
+					t.Errorf("file %d path = %q, want %q", i, file.path(), tt.paths[i])
+				}
+				if file.status != tt.status[i] {
+					t.Errorf("file %d status = %q, want %q", i, file.status, tt.status[i])
+				}
+				if file.binary != tt.binary[i] {
+					t.Errorf("file %d binary = %v, want %v", i, file.binary, tt.binary[i])
+				}
+			}
+
+			added, removed := files[0].changedLines()
+			if strings.Join(added, ",") != strings.Join(tt.added, ",") {
+				t.Errorf("added lines = %v, want %v", added, tt.added)
+			}
+			if removed != tt.removed {
+				t.Errorf("removed lines = %d, want %d", removed, tt.removed)
+			}
+		})
+	}
+}
+
+func TestParseUnifiedDiffErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{
+			name: "no file headers",
+			text: "just some text\n",
+			err:  "no file headers found",
+		},
+		{
+			name: "hunk ended early",
+			text: "--- a/x.go\n" +
+				"+++ b/x.go\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" a\n" +
+				"oops\n",
+			err: "line 5: hunk ended early, expected 2 more old and 2 more new lines",
+		},
+		{
+			name: "malformed hunk header",
+			text: "--- a/x.go\n" +
+				"+++ b/x.go\n" +
+				"@@ -one +1 @@\n",
+			err: `line 3: malformed hunk header "@@ -one +1 @@": invalid range "-one"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseUnifiedDiff(tt.text)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseUnifiedDiff error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// hunkFile File with hunks of the given numbers of added lines
+func hunkFile(name string, sizes ...int) *diffFile {
+	file := &diffFile{oldPath: name, newPath: name, status: "modified"}
+	start := 1
+	for _, size := range sizes {
+		hunk := diffHunk{header: fmt.Sprintf("@@ -%d,0 +%d,%d @@", start, start, size), newStart: start, newLines: size}
+		for i := range size {
+			hunk.lines = append(hunk.lines, fmt.Sprintf("+line %d", start+i))
+		}
+		file.hunks = append(file.hunks, hunk)
+		start += size
+	}
+	return file
+}
+
+func TestRenderDiffFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      *diffFile
+		limit     int
+		used      int
+		truncated bool
+		marker    string
+	}{
+		{name: "within the limit", file: hunkFile("a.go", 2, 3), limit: 10, used: 5},
+		{name: "exactly the limit", file: hunkFile("a.go", 2, 3), limit: 5, used: 5},
+		{
+			name: "later hunks omitted", file: hunkFile("a.go", 2, 3, 4), limit: 4, used: 2, truncated: true,
+			marker: "[truncated: 2 more hunks with 7 lines omitted]",
+		},
+		{
+			name: "first hunk cut short", file: hunkFile("a.go", 6), limit: 4, used: 4, truncated: true,
+			marker: "[truncated: 2 more lines of this hunk omitted]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, used, truncated := renderDiffFile(tt.file, tt.limit)
+			if used != tt.used || truncated != tt.truncated {
+				t.Errorf("renderDiffFile = %d lines, truncated %v, want %d, %v", used, truncated, tt.used, tt.truncated)
+			}
+			if !strings.HasPrefix(text, "--- a/a.go\n+++ b/a.go\n") {
+				t.Errorf("text does not start with the file headers:\n%s", text)
+			}
+			if tt.marker != "" && !strings.HasSuffix(text, tt.marker+"\n") {
+				t.Errorf("text does not end with %q:\n%s", tt.marker, text)
+			}
+			if !tt.truncated && strings.Contains(text, "[truncated") {
+				t.Errorf("text has a truncation marker:\n%s", text)
+			}
+		})
+	}
+}
+
+func TestDiffAttachment(t *testing.T) {
+	t.Run("too many files", func(t *testing.T) {
+		files := make([]*diffFile, maxDiffFiles+3)
+		for i := range files {
+			files[i] = hunkFile(fmt.Sprintf("f%d.go", i), 1)
+		}
+		summary, messages := diffAttachment(files)
+		if len(messages) != maxDiffFiles {
+			t.Errorf("attached %d files, want %d", len(messages), maxDiffFiles)
+		}
+		if !strings.HasSuffix(summary, "- [truncated: 3 more changed files omitted]") {
+			t.Errorf("summary does not end with the omitted files:\n%s", summary)
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		// Every file takes up to maxDiffFileLines of the maxDiffLines budget
+		files := []*diffFile{hunkFile("big.go", maxDiffFileLines+10)}
+		for i := 1; i < maxDiffLines/maxDiffFileLines; i++ {
+			files = append(files, hunkFile(fmt.Sprintf("f%d.go", i), maxDiffFileLines))
+		}
+		files = append(files,
+			hunkFile("late.go", 1),
+			&diffFile{oldPath: "logo.png", newPath: "logo.png", status: "modified", binary: true},
+		)
+
+		summary, messages := diffAttachment(files)
+		lines := strings.Split(summary, "\n")
+		if len(lines) != len(files) {
+			t.Fatalf("summary has %d lines, want %d:\n%s", len(lines), len(files), summary)
+		}
+		for i, want := range map[int]string{
+			0:              "- big.go (Go, modified): added or changed lines 1-510 [truncated]",
+			1:              "- f1.go (Go, modified): added or changed lines 1-500",
+			len(files) - 2: "- late.go (Go, modified): added or changed lines 1 [truncated: diff not attached, size limit reached]",
+			len(files) - 1: "- logo.png (text, modified): binary file, not attached",
+		} {
+			if lines[i] != want {
+				t.Errorf("summary line %d = %q, want %q", i, lines[i], want)
+			}
+		}
+		if len(messages) != len(files)-2 {
+			t.Fatalf("attached %d files, want %d", len(messages), len(files)-2)
+		}
+		resource := messages[0].Content.(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+		if resource.URI != "diff:///big.go" || resource.Meta["truncated"] != true {
+			t.Errorf("first attachment = %s, truncated %v", resource.URI, resource.Meta["truncated"])
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// markdownMIMEType MIME type of documentation resources
+const markdownMIMEType = "text/markdown"
+
+// docSection A heading-delimited section of a markdown document
+type docSection struct {
+	Anchor string
+	Title  string
+	Level  int
+	Body   string
+}
+
+// doc A markdown document split into its sections
+type doc struct {
+	Name     string
+	Title    string
+	Content  string
+	Sections []docSection
+}
+
+// DocLibrary Documentation library publishing markdown files as docs:// resources
+type DocLibrary struct {
+	docs  map[string]doc
+	names []string
+}
+
+// NewDocLibrary Loads the top-level markdown files of each source; later sources override earlier ones with the same name
+func NewDocLibrary(sources ...fs.FS) (*DocLibrary, error) {
+	library := &DocLibrary{docs: make(map[string]doc)}
+
+	for _, source := range sources {
+		matches, err := fs.Glob(source, "*.md")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list docs: %w", err)
+		}
+
+		for _, match := range matches {
+			content, err := fs.ReadFile(source, match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read doc %s: %w", match, err)
+			}
+
+			name := strings.ToLower(strings.TrimSuffix(path.Base(match), path.Ext(match)))
+			library.docs[name] = parseDoc(name, string(content))
+		}
+	}
+
+	for name := range library.docs {
+		library.names = append(library.names, name)
+	}
+	sort.Strings(library.names)
+
+	return library, nil
+}
+
+// Resources One docs://{name} resource per document plus the docs://toc table of contents
+func (l *DocLibrary) Resources() []server.ServerResource {
+	resources := []server.ServerResource{
+		{
+			Resource: mcp.NewResource(
+				"docs://toc",
+				"Documentation Table of Contents",
+				mcp.WithResourceDescription("Table of contents of all documentation with links to individual sections"),
+				mcp.WithMIMEType(markdownMIMEType),
+			),
+			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return []mcp.ResourceContents{
+					mcp.TextResourceContents{
+						URI:      request.Params.URI,
+						MIMEType: markdownMIMEType,
+						Text:     l.tableOfContents(),
+					},
+				}, nil
+			},
+		},
+	}
+
+	for _, name := range l.names {
+		d := l.docs[name]
+		resources = append(resources, server.ServerResource{
+			Resource: mcp.NewResource(
+				"docs://"+name,
+				d.Title,
+				mcp.WithResourceDescription(fmt.Sprintf("Project documentation: %s", d.Title)),
+				mcp.WithMIMEType(markdownMIMEType),
+			),
+			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return []mcp.ResourceContents{
+					mcp.TextResourceContents{
+						URI:      request.Params.URI,
+						MIMEType: markdownMIMEType,
+						Text:     d.Content,
+					},
+				}, nil
+			},
+		})
+	}
+
+	return resources
+}
+
+// SectionTemplate Resource template returning a single documentation section by heading anchor
+func (l *DocLibrary) SectionTemplate(completions *Completions) server.ServerResourceTemplate {
+	const uriTemplate = "docs://{name}/{section}"
+
+	template := mcp.NewResourceTemplate(
+		uriTemplate,
+		"Documentation Section",
+		mcp.WithTemplateDescription("A single documentation section addressed by its heading anchor (see docs://toc)"),
+		mcp.WithTemplateMIMEType(markdownMIMEType),
+	)
+
+	completions.AddResourceArgument(uriTemplate, "name", PrefixCompletion(l.names...))
+	completions.AddResourceArgument(uriTemplate, "section", func(ctx context.Context, value string, resolved map[string]string) []string {
+		d, exists := l.docs[resolved["name"]]
+		if !exists {
+			return nil
+		}
+		anchors := make([]string, 0, len(d.Sections))
+		for _, section := range d.Sections {
+			anchors = append(anchors, section.Anchor)
+		}
+		return filterPrefix(anchors, value)
+	})
+
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		name := templateArgument(request, "name")
+		anchor := templateArgument(request, "section")
+
+		d, exists := l.docs[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown doc %q, valid names are %s: %w",
+				name, strings.Join(l.names, ", "), server.ErrResourceNotFound)
+		}
+
+		for _, section := range d.Sections {
+			if section.Anchor == anchor {
+				return []mcp.ResourceContents{
+					mcp.TextResourceContents{
+						URI:      request.Params.URI,
+						MIMEType: markdownMIMEType,
+						Text:     section.Body,
+					},
+				}, nil
+			}
+		}
+
+		return nil, fmt.Errorf("unknown section %q in doc %q, see docs://toc: %w",
+			anchor, name, server.ErrResourceNotFound)
+	}
+
+	return server.ServerResourceTemplate{
+		Template: template,
+		Handler:  handler,
+	}
+}
+
+// tableOfContents Markdown table of contents linking every document and section
+func (l *DocLibrary) tableOfContents() string {
+	var b strings.Builder
+	b.WriteString("# Documentation\n")
+
+	for _, name := range l.names {
+		d := l.docs[name]
+		fmt.Fprintf(&b, "\n## [%s](docs://%s)\n\n", d.Title, name)
+		for _, section := range d.Sections {
+			indent := strings.Repeat("  ", max(section.Level-1, 0))
+			fmt.Fprintf(&b, "%s- [%s](docs://%s/%s)\n", indent, section.Title, name, section.Anchor)
+		}
+	}
+
+	return b.String()
+}
+
+// heading A markdown heading located by line index
+type heading struct {
+	line  int
+	level int
+	title string
+}
+
+// parseDoc Splits markdown into sections at each heading, ignoring lines inside fenced code blocks
+func parseDoc(name, content string) doc {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	var headings []heading
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		if level, title, ok := parseHeading(line); ok {
+			headings = append(headings, heading{line: i, level: level, title: title})
+		}
+	}
+
+	d := doc{Name: name, Title: name, Content: content}
+	seen := make(map[string]int)
+
+	for i, h := range headings {
+		if i == 0 && h.level == 1 {
+			d.Title = h.title
+		}
+
+		// A section runs until the next heading of the same or a higher level
+		end := len(lines)
+		for _, next := range headings[i+1:] {
+			if next.level <= h.level {
+				end = next.line
+				break
+			}
+		}
+
+		anchor := headingAnchor(h.title)
+		if n := seen[anchor]; n > 0 {
+			seen[anchor]++
+			anchor = fmt.Sprintf("%s-%d", anchor, n)
+		} else {
+			seen[anchor] = 1
+		}
+
+		d.Sections = append(d.Sections, docSection{
+			Anchor: anchor,
+			Title:  h.title,
+			Level:  h.level,
+			Body:   strings.TrimRight(strings.Join(lines[h.line:end], "\n"), "\n") + "\n",
+		})
+	}
+
+	return d
+}
+
+// parseHeading Level and title of an ATX heading line
+func parseHeading(line string) (int, string, bool) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level >= len(line) || line[level] != ' ' {
+		return 0, "", false
+	}
+
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+	return level, title, title != ""
+}
+
+// headingAnchor GitHub-style anchor for a heading title
+func headingAnchor(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
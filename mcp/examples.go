@@ -0,0 +1,206 @@
+package mcp
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// examplesSuffix Suffix of files adding few-shot examples to a prompt defined elsewhere, e.g. math_tutor.examples.yaml
+const examplesSuffix = ".examples"
+
+// promptMessageSpec A single message of a few-shot example as written in a prompt file
+type promptMessageSpec struct {
+	Role    string `yaml:"role"`
+	Content string `yaml:"content"`
+}
+
+// promptExample A few-shot exchange included when every argument named in When has one of the listed values
+type promptExample struct {
+	When     map[string][]string `yaml:"when"`
+	Messages []promptMessageSpec `yaml:"messages"`
+}
+
+// compiledMessage A few-shot message with its compiled content template
+type compiledMessage struct {
+	role    mcp.Role
+	content *template.Template
+}
+
+// compiledExample A few-shot example ready to render
+type compiledExample struct {
+	when     map[string][]string
+	messages []compiledMessage
+}
+
+// matches Reports whether the example applies to the normalized arguments
+func (e compiledExample) matches(args map[string]string) bool {
+	for name, values := range e.when {
+		matched := false
+		for _, value := range values {
+			if slug(value) == slug(args[name]) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// compileExamples Compiles the examples of a prompt, checking roles and that conditions name declared arguments
+func compileExamples(file string, node *yaml.Node, examples []promptExample, arguments map[string]bool) ([]compiledExample, error) {
+	compiled := make([]compiledExample, 0, len(examples))
+
+	for i, example := range examples {
+		exampleNode := node.Content[i]
+		if len(example.Messages) == 0 {
+			return nil, fmt.Errorf("%s:%d: example without messages", file, exampleNode.Line)
+		}
+		for name := range example.When {
+			if !arguments[name] {
+				return nil, fmt.Errorf("%s:%d: example condition on undeclared argument %q", file, exampleNode.Line, name)
+			}
+		}
+
+		messagesNode := mappingValue(exampleNode, "messages")
+		result := compiledExample{when: example.When}
+		for j, message := range example.Messages {
+			messageNode := messagesNode.Content[j]
+
+			role, err := parseRole(message.Role)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", file, messageNode.Line, err)
+			}
+			if strings.TrimSpace(message.Content) == "" {
+				return nil, fmt.Errorf("%s:%d: example message without content", file, messageNode.Line)
+			}
+
+			content, err := compilePromptTemplate(file, templateLine(mappingValue(messageNode, "content")), message.Content)
+			if err != nil {
+				return nil, err
+			}
+			result.messages = append(result.messages, compiledMessage{role: role, content: content})
+		}
+		compiled = append(compiled, result)
+	}
+
+	return compiled, nil
+}
+
+// loadExampleFiles Appends the examples of every *.examples.yaml file in a source to the prompt it names, or to every version of it.
+//
+// Example files under locales/{locale}/ go to the translation of the prompt
+// to that locale, which must translate its template.
+func (l *PromptLibrary) loadExampleFiles(source fs.FS) error {
+	dirs := []string{"."}
+	locales, err := fs.Glob(source, path.Join(localesDir, "*"))
+	if err != nil {
+		return fmt.Errorf("failed to list prompt examples: %w", err)
+	}
+	dirs = append(dirs, locales...)
+
+	for _, dir := range dirs {
+		locale := ""
+		if dir != "." {
+			locale = path.Base(dir)
+		}
+
+		for _, ext := range []string{".yaml", ".yml"} {
+			matches, err := fs.Glob(source, path.Join(dir, "*"+examplesSuffix+ext))
+			if err != nil {
+				return fmt.Errorf("failed to list prompt examples: %w", err)
+			}
+
+			for _, match := range matches {
+				name := strings.TrimSuffix(path.Base(match), examplesSuffix+ext)
+				definitions := l.targets(name)
+				if len(definitions) == 0 {
+					return fmt.Errorf("%s: examples for unknown prompt %q", match, name)
+				}
+				if locale != "" {
+					definitions = slices.DeleteFunc(definitions, func(definition *promptDefinition) bool {
+						return definition.translations[locale] == nil || definition.translations[locale].body == nil
+					})
+					if len(definitions) == 0 {
+						return fmt.Errorf("%s: examples for prompt %q, whose template is not translated to %s", match, name, locale)
+					}
+				}
+
+				examples, node, err := readExampleFile(source, match)
+				if err != nil {
+					return err
+				}
+				if node == nil {
+					continue
+				}
+
+				for _, definition := range definitions {
+					compiled, err := compileExamples(match, node, examples, definition.argumentNames())
+					if err != nil {
+						return err
+					}
+					if err := checkExamples(compiled, definition.sampleArguments()); err != nil {
+						return err
+					}
+					if locale == "" {
+						definition.examples = append(definition.examples, compiled...)
+					} else {
+						translated := definition.translations[locale]
+						translated.examples = append(translated.examples, compiled...)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// readExampleFile Decodes an example file, returning its examples and their YAML node, or a nil node for an empty file
+func readExampleFile(source fs.FS, file string) ([]promptExample, *yaml.Node, error) {
+	data, err := fs.ReadFile(source, file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read prompt examples %s: %w", file, err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil, nil
+	}
+
+	var examples []promptExample
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&examples); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return examples, root.Content[0], nil
+}
+
+// checkExamples Executes every example template with sample arguments so that errors surface at startup
+func checkExamples(examples []compiledExample, sample map[string]string) error {
+	for _, example := range examples {
+		for _, message := range example.messages {
+			if _, err := executeTemplate(message.content, sample); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isExampleFile Reports whether a file holds examples rather than a prompt definition
+func isExampleFile(file string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(file, path.Ext(file)), examplesSuffix)
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// examplePrompt Prompt file with an English few-shot example and one for the fr locale
+const examplePrompt = "---\nname: tutor\narguments:\n  - name: topic\nexamples:\n" +
+	"  - messages:\n      - role: user\n        content: What is {{.topic}}?\n" +
+	"  - when:\n      locale: [fr]\n    messages:\n      - role: user\n        content: Qu'est-ce que {{.topic}} ?\n" +
+	"---\nTeach {{.topic}}\n"
+
+func TestLocalizedExamples(t *testing.T) {
+	library, err := NewPromptLibrary(fstest.MapFS{
+		"tutor.md":                       &fstest.MapFile{Data: []byte(examplePrompt)},
+		"locales/es/tutor.md":            &fstest.MapFile{Data: []byte("Enseña {{.topic}}\n")},
+		"locales/es/tutor.examples.yaml": &fstest.MapFile{Data: []byte("- messages:\n    - role: user\n      content: ¿Qué es {{.topic}}?\n")},
+		"locales/fr/tutor.md":            &fstest.MapFile{Data: []byte("Enseigne {{.topic}}\n")},
+		"locales/de/tutor.yaml":          &fstest.MapFile{Data: []byte("title: Nachhilfe\n")},
+	})
+	if err != nil {
+		t.Fatalf("NewPromptLibrary failed: %v", err)
+	}
+	definition := library.prompts["tutor"]
+	if locales := definition.exampleLocales(); !slices.Equal(locales, []string{"en", "de", "es", "fr"}) {
+		t.Errorf("example locales = %v, want en, de, es and fr", locales)
+	}
+
+	tests := []struct {
+		locale   string
+		examples []string
+	}{
+		{locale: "en", examples: []string{"What is sets?"}},
+		{locale: "es", examples: []string{"¿Qué es sets?"}},
+		// Without translated examples, only those conditioned on the locale remain
+		{locale: "fr", examples: []string{"Qu'est-ce que sets ?"}},
+		// Only the title is translated, so the English body keeps its examples
+		{locale: "de", examples: []string{"What is sets?"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			request := mcp.GetPromptRequest{}
+			request.Params.Arguments = map[string]string{"topic": "sets", "locale": tt.locale}
+			result, err := definition.handle(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handle failed: %v", err)
+			}
+			var examples []string
+			for _, message := range result.Messages[1:] {
+				examples = append(examples, message.Content.(mcp.TextContent).Text)
+			}
+			if !slices.Equal(examples, tt.examples) {
+				t.Errorf("examples = %q, want %q", examples, tt.examples)
+			}
+		})
+	}
+}
+
+func TestLocalizedExamplesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		err   string
+	}{
+		{
+			name:  "untranslated locale",
+			files: fstest.MapFS{"locales/es/tutor.examples.yaml": &fstest.MapFile{Data: []byte("- messages:\n    - role: user\n      content: Hola\n")}},
+			err:   `locales/es/tutor.examples.yaml: examples for prompt "tutor", whose template is not translated to es`,
+		},
+		{
+			name: "translated title only",
+			files: fstest.MapFS{
+				"locales/es/tutor.yaml":          &fstest.MapFile{Data: []byte("title: Tutoría\n")},
+				"locales/es/tutor.examples.yaml": &fstest.MapFile{Data: []byte("- messages:\n    - role: user\n      content: Hola\n")},
+			},
+			err: "whose template is not translated to es",
+		},
+		{
+			name: "undeclared argument",
+			files: fstest.MapFS{
+				"locales/es/tutor.md":            &fstest.MapFile{Data: []byte("Enseña {{.topic}}\n")},
+				"locales/es/tutor.examples.yaml": &fstest.MapFile{Data: []byte("- messages:\n    - role: user\n      content: \"{{.level}}\"\n")},
+			},
+			err: "locales/es/tutor.examples.yaml:3:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.files["tutor.md"] = &fstest.MapFile{Data: []byte(examplePrompt)}
+			_, err := NewPromptLibrary(tt.files)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("NewPromptLibrary error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// workspaceURIPrefix Scheme prefix of filesystem-backed resource URIs.
+//
+// The paths are relative to the resource root, so they get a scheme of their
+// own: clients read file:/// URIs as absolute paths on their host.
+const workspaceURIPrefix = "workspace:///"
+
+// sniffLen Number of leading bytes inspected for content sniffing
+const sniffLen = 512
+
+// ErrOutsideRoot Returned when a path resolves outside the configured resource root
+var ErrOutsideRoot = errors.New("path escapes resource root")
+
+// ErrFileTooLarge Returned when a file exceeds the configured size limit
+var ErrFileTooLarge = errors.New("file exceeds size limit")
+
+// FileResources Filesystem resources exposing every regular file below a root directory
+type FileResources struct {
+	root          string
+	maxSize       int64
+	includeHidden bool
+}
+
+// NewFileResources Creates filesystem resources for root, rejecting files larger than maxSize bytes and, unless includeHidden is set, hidden files and directories such as .git/ and .env
+func NewFileResources(root string, maxSize int64, includeHidden bool) (*FileResources, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve resource root %q: %w", root, err)
+	}
+
+	// Resolve the root itself so that containment checks compare resolved paths
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve resource root %q: %w", root, err)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat resource root %q: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("resource root %q is not a directory", root)
+	}
+
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid file size limit %d", maxSize)
+	}
+
+	return &FileResources{
+		root:          resolved,
+		maxSize:       maxSize,
+		includeHidden: includeHidden,
+	}, nil
+}
+
+// Root Resolved absolute path of the resource root
+func (f *FileResources) Root() string {
+	return f.root
+}
+
+// fileEntry Scanned file resource with the metadata used to detect changes
+type fileEntry struct {
+	resource server.ServerResource
+	modTime  time.Time
+	size     int64
+}
+
+// scan Walks the root and returns the servable files keyed by resource URI
+func (f *FileResources) scan() (map[string]fileEntry, error) {
+	files := make(map[string]fileEntry)
+
+	err := filepath.WalkDir(f.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != f.root && !f.includeHidden && isHidden(d.Name()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(f.root, p)
+		if err != nil {
+			return err
+		}
+
+		// Skip symlinks pointing outside the root, directories and oversized files
+		resolved, info, err := f.resolve(rel)
+		if err != nil || info.IsDir() || info.Size() > f.maxSize {
+			return nil
+		}
+
+		resource := f.resource(rel, resolved, info)
+		files[resource.Resource.URI] = fileEntry{
+			resource: resource,
+			modTime:  info.ModTime(),
+			size:     info.Size(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan resource root %q: %w", f.root, err)
+	}
+
+	return files, nil
+}
+
+// resource Builds the server resource for a file relative to the root
+func (f *FileResources) resource(rel, resolved string, info fs.FileInfo) server.ServerResource {
+	resource := mcp.NewResource(
+		FileURI(rel),
+		filepath.ToSlash(rel),
+		mcp.WithResourceDescription(fmt.Sprintf("Local file %s", filepath.ToSlash(rel))),
+		mcp.WithMIMEType(detectMIMEType(resolved, nil)),
+		mcp.WithResourceSize(info.Size()),
+	)
+
+	return server.ServerResource{
+		Resource: resource,
+		Handler:  f.read,
+	}
+}
+
+// read Reads a file resource, returning text or blob contents depending on its MIME type
+func (f *FileResources) read(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	rel, err := filePathFromURI(request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, info, err := f.resolve(rel)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory: %w", rel, server.ErrResourceNotFound)
+	}
+	if info.Size() > f.maxSize {
+		return nil, fmt.Errorf("%s is %d bytes, limit is %d: %w", rel, info.Size(), f.maxSize, ErrFileTooLarge)
+	}
+
+	file, err := os.Open(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", rel, err)
+	}
+	defer file.Close()
+
+	// Read one byte past the limit to catch files that grew after the stat
+	data, err := io.ReadAll(io.LimitReader(file, f.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+	if int64(len(data)) > f.maxSize {
+		return nil, fmt.Errorf("%s exceeds limit of %d bytes: %w", rel, f.maxSize, ErrFileTooLarge)
+	}
+
+	mimeType := detectMIMEType(resolved, data)
+
+	if isTextContent(mimeType, data) {
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: mimeType,
+				Text:     string(data),
+			},
+		}, nil
+	}
+
+	return []mcp.ResourceContents{
+		mcp.BlobResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString(data),
+		},
+	}, nil
+}
+
+// resolve Maps a root-relative path to its resolved location, refusing traversal and symlink escapes, and hidden paths unless they are included
+func (f *FileResources) resolve(rel string) (string, fs.FileInfo, error) {
+	if filepath.IsAbs(rel) || !filepath.IsLocal(rel) {
+		return "", nil, fmt.Errorf("%s: %w", rel, ErrOutsideRoot)
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Join(f.root, rel))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil, fmt.Errorf("%s: %w", rel, server.ErrResourceNotFound)
+		}
+		return "", nil, fmt.Errorf("failed to resolve %s: %w", rel, err)
+	}
+
+	within, err := filepath.Rel(f.root, resolved)
+	if err != nil || !filepath.IsLocal(within) {
+		return "", nil, fmt.Errorf("%s: %w", rel, ErrOutsideRoot)
+	}
+	// A symlink may also lead from a visible name to a hidden file
+	if !f.includeHidden && (isHiddenPath(rel) || isHiddenPath(within)) {
+		return "", nil, fmt.Errorf("%s is hidden: %w", rel, server.ErrResourceNotFound)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to stat %s: %w", rel, err)
+	}
+
+	return resolved, info, nil
+}
+
+// FileURI workspace:/// resource URI for a path relative to the resource root
+func FileURI(rel string) string {
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return workspaceURIPrefix + strings.Join(segments, "/")
+}
+
+// isHidden Reports whether a file or directory name is hidden, i.e. starts with a dot
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// isHiddenPath Reports whether any element of a relative path is hidden
+func isHiddenPath(rel string) bool {
+	return slices.ContainsFunc(strings.Split(filepath.ToSlash(rel), "/"), isHidden)
+}
+
+// filePathFromURI Root-relative path encoded in a file resource URI
+func filePathFromURI(uri string) (string, error) {
+	if !strings.HasPrefix(uri, workspaceURIPrefix) {
+		return "", fmt.Errorf("unsupported resource URI %q: %w", uri, server.ErrResourceNotFound)
+	}
+
+	unescaped, err := url.PathUnescape(strings.TrimPrefix(uri, workspaceURIPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid resource URI %q: %w", uri, err)
+	}
+
+	cleaned := path.Clean(unescaped)
+	if cleaned == "." || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return "", fmt.Errorf("%s: %w", unescaped, ErrOutsideRoot)
+	}
+
+	return filepath.FromSlash(cleaned), nil
+}
+
+// sourceMIMETypes MIME types for common note and source extensions that system MIME tables often lack
+var sourceMIMETypes = map[string]string{
+	".md":   "text/markdown; charset=utf-8",
+	".go":   "text/x-go; charset=utf-8",
+	".py":   "text/x-python; charset=utf-8",
+	".rs":   "text/x-rust; charset=utf-8",
+	".java": "text/x-java; charset=utf-8",
+	".c":    "text/x-c; charset=utf-8",
+	".h":    "text/x-c; charset=utf-8",
+	".cpp":  "text/x-c++; charset=utf-8",
+	".ts":   "text/x-typescript; charset=utf-8",
+	".sh":   "text/x-shellscript; charset=utf-8",
+	".tex":  "text/x-tex; charset=utf-8",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".toml": "application/toml",
+}
+
+// detectMIMEType MIME type from the file extension, falling back to content sniffing
+func detectMIMEType(name string, data []byte) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if mimeType, ok := sourceMIMETypes[ext]; ok {
+		return mimeType
+	}
+	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+		return mimeType
+	}
+
+	if data == nil {
+		head, err := readHead(name)
+		if err != nil {
+			return "application/octet-stream"
+		}
+		data = head
+	}
+
+	return http.DetectContentType(data)
+}
+
+// readHead First sniffLen bytes of a file
+func readHead(name string) ([]byte, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, sniffLen))
+}
+
+// isTextContent Reports whether content of the given MIME type should be returned as text
+func isTextContent(mimeType string, data []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = mimeType
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+	case mediaType == "application/json", mediaType == "application/xml",
+		mediaType == "application/javascript", mediaType == "application/x-yaml",
+		mediaType == "application/yaml", mediaType == "application/toml",
+		strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+	default:
+		return false
+	}
+
+	return utf8.Valid(data)
+}
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fileResourceTree Resource root with visible, hidden and symlinked files, next to a directory outside it
+func fileResourceTree(t *testing.T) string {
+	t.Helper()
+	base := t.TempDir()
+	write := func(content string, parts ...string) {
+		t.Helper()
+		name := filepath.Join(append([]string{base}, parts...)...)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	symlink := func(target string, parts ...string) {
+		t.Helper()
+		if err := os.Symlink(target, filepath.Join(append([]string{base}, parts...)...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("notes", "root", "notes.md")
+	write("lesson", "root", "sub", "lesson.md")
+	write("SECRET=1", "root", ".env")
+	write("[core]", "root", ".git", "config")
+	write("outside", "outside", "secret.txt")
+
+	// inside stays in the root, the others leave it or lead to hidden files
+	symlink("notes.md", "root", "inside.md")
+	symlink(filepath.Join(base, "outside", "secret.txt"), "root", "escape.txt")
+	symlink(filepath.Join(base, "outside"), "root", "escape")
+	symlink(".env", "root", "env.txt")
+	return filepath.Join(base, "root")
+}
+
+func TestFileResourcesScan(t *testing.T) {
+	tests := []struct {
+		name          string
+		includeHidden bool
+		want          []string
+	}{
+		{
+			name: "hidden entries skipped",
+			want: []string{"workspace:///inside.md", "workspace:///notes.md", "workspace:///sub/lesson.md"},
+		},
+		{
+			name:          "hidden entries included",
+			includeHidden: true,
+			want: []string{
+				"workspace:///.env", "workspace:///.git/config", "workspace:///env.txt",
+				"workspace:///inside.md", "workspace:///notes.md", "workspace:///sub/lesson.md",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := NewFileResources(fileResourceTree(t), 1<<20, tt.includeHidden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scanned, err := files.scan()
+			if err != nil {
+				t.Fatal(err)
+			}
+			uris := sortedKeys(scanned)
+			if !slices.Equal(uris, tt.want) {
+				t.Errorf("scan = %v, want %v", uris, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileResourcesRead(t *testing.T) {
+	root := fileResourceTree(t)
+	files, err := NewFileResources(root, 1<<20, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		uri  string
+		want string
+		err  error
+	}{
+		{name: "file", uri: "workspace:///notes.md", want: "notes"},
+		{name: "nested file", uri: "workspace:///sub/lesson.md", want: "lesson"},
+		{name: "symlink within the root", uri: "workspace:///inside.md", want: "notes"},
+		{name: "parent reference", uri: "workspace:///../outside/secret.txt", err: ErrOutsideRoot},
+		{name: "escaped parent reference", uri: "workspace:///%2e%2e/outside/secret.txt", err: ErrOutsideRoot},
+		{name: "parent reference below a directory", uri: "workspace:///sub/../../outside/secret.txt", err: ErrOutsideRoot},
+		{name: "absolute path", uri: "workspace:////etc/hosts", err: ErrOutsideRoot},
+		{name: "symlink to a file outside", uri: "workspace:///escape.txt", err: ErrOutsideRoot},
+		{name: "symlink to a directory outside", uri: "workspace:///escape/secret.txt", err: ErrOutsideRoot},
+		{name: "hidden file", uri: "workspace:///.env", err: server.ErrResourceNotFound},
+		{name: "file in a hidden directory", uri: "workspace:///.git/config", err: server.ErrResourceNotFound},
+		{name: "symlink to a hidden file", uri: "workspace:///env.txt", err: server.ErrResourceNotFound},
+		{name: "file scheme", uri: "file:///notes.md", err: server.ErrResourceNotFound},
+		{name: "missing", uri: "workspace:///missing.md", err: server.ErrResourceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.ReadResourceRequest{}
+			request.Params.URI = tt.uri
+			contents, err := files.read(context.Background(), request)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("read(%q) = %v, %v, want error %v", tt.uri, contents, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("read(%q) failed: %v", tt.uri, err)
+			}
+			if text := contents[0].(mcp.TextResourceContents).Text; text != tt.want {
+				t.Errorf("read(%q) = %q, want %q", tt.uri, text, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+//go:embed data/formulas/*.md
+var formulaSheetFiles embed.FS
+
+// formulaSheet A markdown formula sheet for one math topic
+type formulaSheet struct {
+	Topic   string
+	Title   string
+	Content string
+}
+
+// URI Resource URI of the formula sheet
+func (s formulaSheet) URI() string {
+	return "math://formulas/" + s.Topic
+}
+
+// loadFormulaSheets Reads the embedded formula sheets once, keyed by topic
+var loadFormulaSheets = sync.OnceValues(func() (map[string]formulaSheet, error) {
+	matches, err := fs.Glob(formulaSheetFiles, "data/formulas/*.md")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list formula sheets: %w", err)
+	}
+
+	sheets := make(map[string]formulaSheet, len(matches))
+	for _, match := range matches {
+		content, err := formulaSheetFiles.ReadFile(match)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read formula sheet %s: %w", match, err)
+		}
+
+		topic := strings.TrimSuffix(path.Base(match), path.Ext(match))
+		sheets[topic] = formulaSheet{
+			Topic:   topic,
+			Title:   parseDoc(topic, string(content)).Title,
+			Content: string(content),
+		}
+	}
+	return sheets, nil
+})
+
+// formulaTopics Sorted topics that have a formula sheet
+func formulaTopics() []string {
+	sheets, err := loadFormulaSheets()
+	if err != nil {
+		return nil
+	}
+
+	topics := make([]string, 0, len(sheets))
+	for topic := range sheets {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// findFormulaSheet Formula sheet for a topic as users write it, e.g. "Linear Algebra" or "linear-algebra"
+func findFormulaSheet(topic string) (formulaSheet, bool) {
+	sheets, err := loadFormulaSheets()
+	if err != nil {
+		return formulaSheet{}, false
+	}
+
+	sheet, exists := sheets[slug(topic)]
+	return sheet, exists
+}
+
+// FormulaSheetResources One math://formulas/{topic} resource per formula sheet, so prompts can attach them
+func FormulaSheetResources() ([]server.ServerResource, error) {
+	sheets, err := loadFormulaSheets()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]server.ServerResource, 0, len(sheets))
+	for _, topic := range formulaTopics() {
+		sheet := sheets[topic]
+		resources = append(resources, server.ServerResource{
+			Resource: mcp.NewResource(
+				sheet.URI(),
+				sheet.Title,
+				mcp.WithResourceDescription(fmt.Sprintf("Key formulas for %s", strings.ReplaceAll(topic, "_", " "))),
+				mcp.WithMIMEType(markdownMIMEType),
+			),
+			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return []mcp.ResourceContents{
+					mcp.TextResourceContents{
+						URI:      request.Params.URI,
+						MIMEType: markdownMIMEType,
+						Text:     sheet.Content,
+					},
+				}, nil
+			},
+		})
+	}
+	return resources, nil
+}
+
+// FormulaSheetTemplate Formula sheet resource template returning the key formulas of a math topic
+func FormulaSheetTemplate(completions *Completions) server.ServerResourceTemplate {
+	const uriTemplate = "math://formulas/{topic}"
+
+	template := mcp.NewResourceTemplate(
+		uriTemplate,
+		"Math Formula Sheet",
+		mcp.WithTemplateDescription("Formula sheet for a math topic (algebra, calculus, geometry, statistics, trigonometry, linear_algebra, differential_equations)"),
+		mcp.WithTemplateMIMEType(markdownMIMEType),
+	)
+
+	completions.AddResourceArgument(uriTemplate, "topic", func(ctx context.Context, value string, resolved map[string]string) []string {
+		return filterPrefix(formulaTopics(), value)
+	})
+
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if _, err := loadFormulaSheets(); err != nil {
+			return nil, err
+		}
+
+		topic := templateArgument(request, "topic")
+		sheet, exists := findFormulaSheet(topic)
+		if !exists {
+			return nil, fmt.Errorf("unknown topic %q, valid topics are %s: %w",
+				topic, strings.Join(formulaTopics(), ", "), server.ErrResourceNotFound)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: markdownMIMEType,
+				Text:     sheet.Content,
+			},
+		}, nil
+	}
+
+	return server.ServerResourceTemplate{
+		Template: template,
+		Handler:  handler,
+	}
+}
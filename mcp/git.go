@@ -0,0 +1,393 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Limits on what a git review collects from the repository
+const (
+	gitTimeout        = 10 * time.Second
+	maxGitOutputBytes = 4 << 20
+	maxGitCommits     = 100
+)
+
+// gitRevisionPattern Characters allowed in a revision range; anything else is rejected before git sees it
+var gitRevisionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/~^@{}+-]*$`)
+
+// ErrNotRepository Returned when a path inside a git root is not a git working tree
+var ErrNotRepository = errors.New("not a git repository")
+
+// GitRoots Directories under which the git review prompt may read repositories
+type GitRoots struct {
+	roots []string
+}
+
+// NewGitRoots Resolves the configured roots, which must be existing directories
+func NewGitRoots(paths ...string) (*GitRoots, error) {
+	roots := &GitRoots{}
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve git root %s: %w", p, err)
+		}
+		resolved, err = filepath.Abs(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve git root %s: %w", p, err)
+		}
+		info, err := os.Stat(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat git root %s: %w", p, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("git root %s is not a directory", p)
+		}
+		roots.roots = append(roots.roots, resolved)
+	}
+	if len(roots.roots) == 0 {
+		return nil, errors.New("no git roots configured")
+	}
+	return roots, nil
+}
+
+// resolve Absolute path of a repository inside one of the roots.
+//
+// Relative paths are tried against each root in order, and the first that
+// names a repository inside a root wins. When none does, the error is that of
+// the first candidate that exists.
+func (g *GitRoots) resolve(repository string) (string, error) {
+	var candidates []string
+	if filepath.IsAbs(repository) {
+		candidates = []string{repository}
+	} else if filepath.IsLocal(repository) {
+		for _, root := range g.roots {
+			candidates = append(candidates, filepath.Join(root, repository))
+		}
+	} else {
+		return "", fmt.Errorf("%s: %w", repository, ErrOutsideRoot)
+	}
+
+	var failure error
+	for _, candidate := range candidates {
+		resolved, err := filepath.EvalSymlinks(candidate)
+		if err != nil {
+			continue
+		}
+		if err := g.checkRepository(resolved); err != nil {
+			if failure == nil {
+				failure = fmt.Errorf("%s: %w", repository, err)
+			}
+			continue
+		}
+		return resolved, nil
+	}
+
+	if failure != nil {
+		return "", failure
+	}
+	return "", fmt.Errorf("repository %s not found in any git root: %w", repository, os.ErrNotExist)
+}
+
+// checkRepository Fails with ErrOutsideRoot unless the resolved path is inside a root, and with ErrNotRepository unless it is a git working tree
+func (g *GitRoots) checkRepository(resolved string) error {
+	for _, root := range g.roots {
+		if within, err := filepath.Rel(root, resolved); err == nil && filepath.IsLocal(within) {
+			if _, err := os.Stat(filepath.Join(resolved, ".git")); err != nil {
+				return ErrNotRepository
+			}
+			return nil
+		}
+	}
+	return ErrOutsideRoot
+}
+
+// repositories Repositories directly at or one level below each root, relative to the first root containing them
+func (g *GitRoots) repositories() []string {
+	var repos []string
+	for _, root := range g.roots {
+		if _, err := os.Stat(filepath.Join(root, ".git")); err == nil {
+			repos = append(repos, root)
+		}
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(root, entry.Name(), ".git")); err == nil {
+				repos = append(repos, filepath.Join(root, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// runGit Runs a read-only git command in a repository, capping its output.
+//
+// External diff drivers, textconv filters and fsmonitor hooks are disabled so
+// that repository configuration cannot make a review run arbitrary commands.
+func runGit(ctx context.Context, dir string, args ...string) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	base := []string{"-C", dir, "-c", "core.fsmonitor=false", "-c", "core.quotePath=false"}
+	cmd := exec.CommandContext(ctx, "git", append(base, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_OPTIONAL_LOCKS=0")
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", false, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return "", false, fmt.Errorf("failed to run git: %w", err)
+	}
+	output, readErr := io.ReadAll(io.LimitReader(stdout, maxGitOutputBytes+1))
+	truncated := len(output) > maxGitOutputBytes
+	if truncated {
+		output = output[:maxGitOutputBytes]
+		// Stop git instead of draining the rest of a huge output
+		cancel()
+	}
+	waitErr := cmd.Wait()
+
+	if readErr != nil {
+		return "", false, fmt.Errorf("failed to read git output: %w", readErr)
+	}
+	if waitErr != nil && !truncated {
+		return "", false, fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return string(output), truncated, nil
+}
+
+// gitRange Revision range for git log; a single revision means that one commit
+func gitRange(revisions string) (string, error) {
+	if !gitRevisionPattern.MatchString(revisions) || strings.Count(revisions, "..") > 1 && !strings.Contains(revisions, "...") {
+		return "", fmt.Errorf("invalid revision range %q, expected e.g. main..HEAD: %w", revisions, mcp.ErrInvalidParams)
+	}
+	if !strings.Contains(revisions, "..") {
+		return revisions + "^!", nil
+	}
+	return revisions, nil
+}
+
+// gitCommitLog Commit hashes, authors, subjects and bodies of a range, newest first
+func gitCommitLog(ctx context.Context, dir, revisions string) (string, int, error) {
+	output, _, err := runGit(ctx, dir, "log", "--no-color", fmt.Sprintf("--max-count=%d", maxGitCommits+1),
+		"--format=%h%x00%an%x00%s%x00%b%x1e", "--end-of-options", revisions, "--")
+	if err != nil {
+		return "", 0, err
+	}
+
+	var b strings.Builder
+	count := 0
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		count++
+		if count > maxGitCommits {
+			fmt.Fprintf(&b, "[truncated: only the newest %d commits are listed]\n", maxGitCommits)
+			break
+		}
+		fmt.Fprintf(&b, "- %s %s (%s)\n", fields[0], fields[2], fields[1])
+		for _, line := range strings.Split(strings.TrimSpace(fields[3]), "\n") {
+			if strings.TrimSpace(line) != "" {
+				fmt.Fprintf(&b, "  %s\n", line)
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n"), min(count, maxGitCommits), nil
+}
+
+// gitDiff Unified diff of a range, or of a single commit against its first parent, cut at the last complete file when the output limit is reached.
+//
+// git diff rev^! is empty for a root commit and for a merge, so a single
+// commit goes through git diff-tree, which diffs a root commit against the
+// empty tree.
+func gitDiff(ctx context.Context, dir, revisions string) (string, error) {
+	args := []string{"diff"}
+	if !strings.Contains(revisions, "..") {
+		args = []string{"diff-tree", "-p", "--root", "--no-commit-id", "--diff-merges=first-parent"}
+	}
+	args = append(args, "--no-color", "--no-ext-diff", "--no-textconv", "--find-renames", "--end-of-options", revisions, "--")
+	output, truncated, err := runGit(ctx, dir, args...)
+	if err != nil {
+		return "", err
+	}
+	if truncated {
+		if cut := strings.LastIndex(output, "\ndiff --git "); cut > 0 {
+			output = output[:cut+1]
+		}
+	}
+	return output, nil
+}
+
+// dominantLanguage Most common language among changed source files, or empty when there is none
+func dominantLanguage(files []*diffFile) string {
+	counts := make(map[string]int)
+	for _, file := range files {
+		switch language := file.language(); language {
+		case "text", "Markdown", "JSON", "YAML", "TOML", "Go module":
+		default:
+			counts[language]++
+		}
+	}
+
+	best := ""
+	for _, language := range sortedKeys(counts) {
+		if counts[language] > counts[best] {
+			best = language
+		}
+	}
+	return best
+}
+
+// GitReviewPrompt Git review prompt building a code_review conversation from a revision range of a local repository
+func GitReviewPrompt(prompts *PromptRegistry, roots *GitRoots, completions *Completions) server.ServerPrompt {
+	prompt := mcp.NewPrompt("git_review",
+		mcp.WithPromptDescription("Reviews the commits of a revision range in a local git repository, collecting commit messages, changed files and diffs from disk"),
+		mcp.WithArgument("repository",
+			mcp.ArgumentDescription("Path of the repository, absolute or relative to a configured git root"),
+			mcp.RequiredArgument(),
+		),
+		mcp.WithArgument("range",
+			mcp.ArgumentDescription("Revision range to review (e.g., main..HEAD); a single revision reviews that commit"),
+			mcp.RequiredArgument(),
+		),
+		mcp.WithArgument("focus",
+			mcp.ArgumentDescription("Primary review focus areas (performance, security, readability, architecture, testing, maintainability, scalability)"),
+		),
+		mcp.WithArgument("experience_level",
+			mcp.ArgumentDescription("Target developer experience level (junior, mid-level, senior, lead, architect)"),
+		),
+		mcp.WithArgument(localeArgumentName,
+			mcp.ArgumentDescription("Language of the review as a language tag (e.g., en, es); falls back to English when there is no translation"),
+		),
+	)
+
+	completions.AddPromptArgument("git_review", "repository", func(ctx context.Context, value string, resolved map[string]string) []string {
+		return filterPrefix(roots.repositories(), value)
+	})
+	completions.AddPromptArgument("git_review", "range", func(ctx context.Context, value string, resolved map[string]string) []string {
+		dir, err := roots.resolve(resolved["repository"])
+		if err != nil {
+			return nil
+		}
+		refs, _, err := runGit(ctx, dir, "for-each-ref", "--format=%(refname:short)", "refs/heads", "refs/tags")
+		if err != nil {
+			return nil
+		}
+		var ranges []string
+		for _, ref := range strings.Fields(refs) {
+			ranges = append(ranges, ref+"..HEAD")
+		}
+		return filterPrefix(ranges, value)
+	})
+	for _, argument := range []string{"focus", "experience_level", localeArgumentName} {
+		completions.AddPromptArgument("git_review", argument, func(ctx context.Context, value string, resolved map[string]string) []string {
+			if review, exists := prompts.Library().lookup("code_review"); exists {
+				for _, declared := range review.spec.Arguments {
+					if declared.Name == argument {
+						return filterPrefix(declared.suggestions(resolved), value)
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	handler := func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		review, exists := prompts.Library().lookup("code_review")
+		if !exists {
+			return nil, errors.New("git_review needs the code_review prompt, which is not loaded")
+		}
+
+		repository := strings.TrimSpace(request.Params.Arguments["repository"])
+		revisions := strings.TrimSpace(request.Params.Arguments["range"])
+		if repository == "" || revisions == "" {
+			return nil, fmt.Errorf("repository and range are required: %w", mcp.ErrInvalidParams)
+		}
+
+		dir, err := roots.resolve(repository)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", err, mcp.ErrInvalidParams)
+		}
+		rangeArg, err := gitRange(revisions)
+		if err != nil {
+			return nil, err
+		}
+
+		log, commits, err := gitCommitLog(ctx, dir, rangeArg)
+		if err != nil {
+			return nil, err
+		}
+		if commits == 0 {
+			return nil, fmt.Errorf("range %s of %s contains no commits: %w", revisions, repository, mcp.ErrInvalidParams)
+		}
+		diff, err := gitDiff(ctx, dir, revisions)
+		if err != nil {
+			return nil, err
+		}
+
+		reviewArgs := map[string]string{
+			"focus":            request.Params.Arguments["focus"],
+			"experience_level": request.Params.Arguments["experience_level"],
+			localeArgumentName: request.Params.Arguments[localeArgumentName],
+			"review_type":      "post-implementation",
+		}
+		if strings.TrimSpace(diff) != "" {
+			reviewArgs["diff"] = diff
+			if files, err := parseUnifiedDiff(diff); err == nil {
+				if language := dominantLanguage(files); language != "" {
+					reviewArgs["language"] = language
+				}
+			}
+		}
+
+		reviewRequest := mcp.GetPromptRequest{}
+		reviewRequest.Params.Name = review.spec.Name
+		reviewRequest.Params.Arguments = reviewArgs
+		result, err := review.handle(ctx, reviewRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		// The commit messages explain intent, so they follow the instruction and precede the examples and diff
+		commitLog := mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(
+			fmt.Sprintf("Commits in %s of %s (%d):\n\n%s", revisions, filepath.Base(dir), commits, log)))
+		messages := append([]mcp.PromptMessage{result.Messages[0], commitLog}, result.Messages[1:]...)
+
+		return mcp.NewGetPromptResult(
+			fmt.Sprintf("Git Review: %s %s", filepath.Base(dir), revisions),
+			messages,
+		), nil
+	}
+
+	return server.ServerPrompt{
+		Prompt:  prompt,
+		Handler: handler,
+	}
+}
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitRootsResolve(t *testing.T) {
+	base := t.TempDir()
+	mkdir := func(parts ...string) string {
+		t.Helper()
+		dir := filepath.Join(append([]string{base}, parts...)...)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	symlink := func(target string, parts ...string) {
+		t.Helper()
+		if err := os.Symlink(target, filepath.Join(append([]string{base}, parts...)...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, second := mkdir("first"), mkdir("second")
+	outside := mkdir("outside")
+	mkdir("outside", ".git")
+
+	// app is a plain directory in the first root and a repository in the second
+	mkdir("first", "app")
+	mkdir("second", "app", ".git")
+	// link leaves the roots from the first root and is a repository in the second
+	symlink(outside, "first", "link")
+	mkdir("second", "link", ".git")
+	// lib is a repository in the first root
+	mkdir("first", "lib", ".git")
+	// docs is a plain directory in both roots, escape leaves them in both
+	mkdir("first", "docs")
+	mkdir("second", "docs")
+	symlink(outside, "first", "escape")
+	symlink(outside, "second", "escape")
+
+	roots, err := NewGitRoots(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The roots may themselves sit behind symlinks, as in macOS temporary directories
+	first, second = roots.roots[0], roots.roots[1]
+
+	tests := []struct {
+		name       string
+		repository string
+		want       string
+		err        error
+	}{
+		{name: "repository in the first root", repository: "lib", want: filepath.Join(first, "lib")},
+		{name: "plain directory before a repository", repository: "app", want: filepath.Join(second, "app")},
+		{name: "escaping symlink before a repository", repository: "link", want: filepath.Join(second, "link")},
+		{name: "absolute path", repository: filepath.Join(first, "lib"), want: filepath.Join(first, "lib")},
+		{name: "plain directory in every root", repository: "docs", err: ErrNotRepository},
+		{name: "escaping symlink in every root", repository: "escape", err: ErrOutsideRoot},
+		{name: "absolute path outside the roots", repository: outside, err: ErrOutsideRoot},
+		{name: "parent reference", repository: "../outside", err: ErrOutsideRoot},
+		{name: "missing", repository: "missing", err: os.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := roots.resolve(tt.repository)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("resolve(%q) = %q, %v, want error %v", tt.repository, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve(%q) failed: %v", tt.repository, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolve(%q) = %q, want %q", tt.repository, got, tt.want)
+			}
+		})
+	}
+}
+
+// testGit Runs git in a directory with a fixed identity, failing the test on errors
+func testGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+// commitFile Writes a file in a repository and commits it
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	testGit(t, dir, "add", name)
+	testGit(t, dir, "commit", "-q", "-m", "Add "+name)
+}
+
+func TestGitDiffSingleCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	testGit(t, dir, "init", "-q", "-b", "main")
+	commitFile(t, dir, "root.txt", "root\n")
+	testGit(t, dir, "checkout", "-q", "-b", "side")
+	commitFile(t, dir, "side.txt", "side\n")
+	testGit(t, dir, "checkout", "-q", "main")
+	commitFile(t, dir, "main.txt", "main\n")
+	testGit(t, dir, "merge", "-q", "--no-edit", "side")
+
+	tests := []struct {
+		name      string
+		revisions string
+		files     []string
+	}{
+		{name: "root commit", revisions: "HEAD~2", files: []string{"root.txt"}},
+		{name: "commit", revisions: "HEAD~1", files: []string{"main.txt"}},
+		{name: "merge against its first parent", revisions: "HEAD", files: []string{"side.txt"}},
+		{name: "range", revisions: "HEAD~2..HEAD~1", files: []string{"main.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rangeArg, err := gitRange(tt.revisions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, commits, err := gitCommitLog(context.Background(), dir, rangeArg); err != nil || commits != 1 {
+				t.Errorf("gitCommitLog = %d commits, %v, want 1", commits, err)
+			}
+
+			diff, err := gitDiff(context.Background(), dir, tt.revisions)
+			if err != nil {
+				t.Fatalf("gitDiff failed: %v", err)
+			}
+			files, err := parseUnifiedDiff(diff)
+			if err != nil {
+				t.Fatalf("diff does not parse: %v\n%s", err, diff)
+			}
+			var paths []string
+			for _, file := range files {
+				paths = append(paths, file.path())
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.files, ",") {
+				t.Errorf("diff changes %v, want %v", paths, tt.files)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultLocale Locale the prompt files themselves are written in
+const defaultLocale = "en"
+
+// localeArgumentName Argument every prompt takes to select a translation
+const localeArgumentName = "locale"
+
+// localesDir Directory of a prompt source holding one directory of translations per locale, e.g. locales/es/math_tutor.md
+const localesDir = "locales"
+
+// localePattern Lowercase language tag naming a translation directory, e.g. es or pt-br
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// translationSpec A prompt translation as written in a translation file.
+//
+// Terms translate curated argument values for the term template function,
+// keyed by the English value.
+type translationSpec struct {
+	Title    string            `yaml:"title"`
+	Terms    map[string]string `yaml:"terms"`
+	Template string            `yaml:"template"`
+}
+
+// translation Compiled title, body and few-shot examples of a prompt in one locale; nil templates fall back to English.
+//
+// Examples do not fall back: English examples would answer in another
+// language than the translated instruction, so a translated body without
+// examples of its own is served without any, see localeExamples.
+type translation struct {
+	title    *template.Template
+	body     *template.Template
+	examples []compiledExample
+}
+
+// localeArgument Declaration of the locale argument added to every prompt
+func localeArgument() promptArgument {
+	return promptArgument{
+		Name:        localeArgumentName,
+		Description: "Language of the prompt as a language tag (e.g., en, es, pt-BR); falls back to English when there is no translation",
+		Default:     defaultLocale,
+		Values:      []string{defaultLocale},
+		MaxLength:   35,
+	}
+}
+
+// normalizeLocale Lowercase form of a language tag with hyphens, e.g. "pt_BR" becomes "pt-br"
+func normalizeLocale(tag string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tag)), "_", "-")
+}
+
+// locale Most specific translated locale for a requested tag, dropping subtags until one matches, else English
+func (d *promptDefinition) locale(requested string) string {
+	tag := normalizeLocale(requested)
+	for tag != "" {
+		if _, exists := d.translations[tag]; exists {
+			return tag
+		}
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	return defaultLocale
+}
+
+// locales Sorted locales the prompt is available in, English first
+func (d *promptDefinition) locales() []string {
+	return append([]string{defaultLocale}, sortedKeys(d.translations)...)
+}
+
+// localeExamples Few-shot examples of the prompt in a locale, before matching them against the other arguments.
+//
+// A translated template replaces the English examples with its own, keeping
+// only those whose conditions name the locale.
+func (d *promptDefinition) localeExamples(locale string) []compiledExample {
+	translated := d.translations[locale]
+	var examples []compiledExample
+	for _, example := range d.examples {
+		if _, conditioned := example.when[localeArgumentName]; conditioned || translated == nil || translated.body == nil {
+			examples = append(examples, example)
+		}
+	}
+	if translated != nil {
+		examples = append(examples, translated.examples...)
+	}
+	return examples
+}
+
+// exampleLocales Locales of the prompt with at least one few-shot example, English first
+func (d *promptDefinition) exampleLocales() []string {
+	locales := []string{}
+	for _, locale := range d.locales() {
+		for _, example := range d.localeExamples(locale) {
+			values, conditioned := example.when[localeArgumentName]
+			if !conditioned || slices.ContainsFunc(values, func(value string) bool { return slug(value) == locale }) {
+				locales = append(locales, locale)
+				break
+			}
+		}
+	}
+	return locales
+}
+
+// updateLocales Offers the loaded translations as the curated values of the locale argument
+func (d *promptDefinition) updateLocales() {
+	for i := range d.spec.Arguments {
+		if d.spec.Arguments[i].Name == localeArgumentName {
+			d.spec.Arguments[i].Values = d.locales()
+		}
+	}
+}
+
+// loadTranslations Attaches the translations under locales/{locale}/ of a source to the prompts they name, or to every version of them
+func (l *PromptLibrary) loadTranslations(source fs.FS) error {
+	entries, err := fs.ReadDir(source, localesDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list prompt translations: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		locale := entry.Name()
+		if !localePattern.MatchString(locale) {
+			return fmt.Errorf("%s: invalid locale directory %q, expected a lowercase language tag such as es or pt-br", localesDir, locale)
+		}
+		if locale == defaultLocale {
+			return fmt.Errorf("%s: prompt files are already %s, translations for it are not needed", path.Join(localesDir, locale), defaultLocale)
+		}
+
+		defined := make(map[string]string)
+		versionSpecific := make(map[*promptDefinition]bool)
+		for _, ext := range promptExtensions {
+			matches, err := fs.Glob(source, path.Join(localesDir, locale, "*"+ext))
+			if err != nil {
+				return fmt.Errorf("failed to list prompt translations: %w", err)
+			}
+
+			for _, match := range matches {
+				// Example files load with the examples of the other prompt files
+				if isExampleFile(match) {
+					continue
+				}
+				name := strings.TrimSuffix(path.Base(match), ext)
+				definitions := l.targets(name)
+				if len(definitions) == 0 {
+					return fmt.Errorf("%s: translation of unknown prompt %q", match, name)
+				}
+				if previous, exists := defined[name]; exists {
+					return fmt.Errorf("%s: prompt %q is already translated to %s in %s", match, name, locale, previous)
+				}
+				defined[name] = match
+
+				data, err := fs.ReadFile(source, match)
+				if err != nil {
+					return fmt.Errorf("failed to read prompt translation %s: %w", match, err)
+				}
+				// Within a source, a translation of name@version takes precedence over one of the unversioned name
+				specific := strings.Contains(name, "@")
+				for _, definition := range definitions {
+					if versionSpecific[definition] && !specific {
+						continue
+					}
+					versionSpecific[definition] = specific
+					translated, err := parseTranslationFile(match, data, definition)
+					if err != nil {
+						return err
+					}
+
+					if definition.translations == nil {
+						definition.translations = make(map[string]*translation)
+					}
+					definition.translations[locale] = translated
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseTranslationFile Parses and compiles a translation, rendering it once with the prompt's sample arguments
+func parseTranslationFile(file string, data []byte, definition *promptDefinition) (*translation, error) {
+	content := string(data)
+	header := content
+	body := ""
+	bodyLine := 0
+
+	if path.Ext(file) == ".md" {
+		var err error
+		header, body, bodyLine, err = splitFrontMatter(file, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(header), &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	var spec translationSpec
+	if len(root.Content) > 0 {
+		decoder := yaml.NewDecoder(strings.NewReader(header))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	if path.Ext(file) == ".md" {
+		if spec.Template != "" {
+			return nil, fmt.Errorf("%s: markdown translations take their template from the body, not the template field", file)
+		}
+		spec.Template = body
+	} else if node := mappingValue(&root, "template"); node != nil {
+		bodyLine = templateLine(node)
+	}
+
+	terms := make(map[string]string, len(spec.Terms))
+	for value, term := range spec.Terms {
+		terms[slug(value)] = term
+	}
+	funcs := maps.Clone(promptFuncs)
+	funcs["term"] = func(value string) string {
+		if term, exists := terms[slug(value)]; exists {
+			return term
+		}
+		return value
+	}
+
+	translated := &translation{}
+	var err error
+	if strings.TrimSpace(spec.Template) != "" {
+		if translated.body, err = compileTemplate(file, bodyLine, spec.Template, funcs); err != nil {
+			return nil, err
+		}
+	}
+	if spec.Title != "" {
+		if translated.title, err = compileTemplate(file, templateLine(mappingValue(&root, "title")), spec.Title, funcs); err != nil {
+			return nil, err
+		}
+	}
+	if translated.body == nil && translated.title == nil {
+		return nil, fmt.Errorf("%s: translation has neither a title nor a template", file)
+	}
+
+	sample := definition.sampleArguments()
+	for _, t := range []*template.Template{translated.body, translated.title} {
+		if t == nil {
+			continue
+		}
+		if _, err := executeTemplate(t, sample); err != nil {
+			return nil, err
+		}
+	}
+
+	return translated, nil
+}
+
+// LocalesResource Resource listing the locales each currently loaded prompt is available in
+func (r *PromptRegistry) LocalesResource() server.ServerResource {
+	resource := mcp.NewResource(
+		"prompts://locales",
+		"Prompt Locales",
+		mcp.WithResourceDescription("Locales each prompt is translated to, and those its few-shot examples are in; other locales fall back to English, and translations without their own examples are served without any"),
+		mcp.WithMIMEType("application/json"),
+	)
+
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		l := r.Library()
+		prompts := make(map[string][]string, len(l.names))
+		examples := make(map[string][]string, len(l.names))
+		for _, name := range l.names {
+			prompts[name] = l.prompts[name].locales()
+			examples[name] = l.prompts[name].exampleLocales()
+		}
+		for name := range l.versions {
+			definition, _ := l.lookup(name)
+			prompts[name] = definition.locales()
+			examples[name] = definition.exampleLocales()
+		}
+
+		content, err := json.MarshalIndent(map[string]interface{}{
+			"default_locale":   defaultLocale,
+			"prompts":          prompts,
+			"example_locales":  examples,
+			"example_fallback": "omitted",
+		}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal prompt locales: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(content),
+			},
+		}, nil
+	}
+
+	return server.ServerResource{
+		Resource: resource,
+		Handler:  handler,
+	}
+}
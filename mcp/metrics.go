@@ -0,0 +1,369 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// prometheusContentType Content type of the Prometheus text exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// latencyBuckets Histogram upper bounds in seconds, matching the Prometheus client defaults
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogram Cumulative-bucket latency histogram
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// observe Records a single observation in seconds
+func (h *histogram) observe(seconds float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(latencyBuckets))
+	}
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// Metrics Request metrics collected from server hooks and rendered in Prometheus text format
+type Metrics struct {
+	sessions *SessionTracker
+
+	mu            sync.Mutex
+	inflight      map[string]time.Time
+	toolCalls     map[string]uint64
+	toolErrors    map[string]uint64
+	toolLatency   map[string]*histogram
+	promptGets    map[string]uint64
+	resourceReads map[string]uint64
+	requestErrors map[string]uint64
+	responseBytes map[string]uint64
+
+	// templates Resource templates whose reads are counted by template name, keyed by URI template like on the server
+	templates map[string]mcp.ResourceTemplate
+}
+
+// NewMetrics Creates an empty metrics collector reporting active sessions from the given tracker
+func NewMetrics(sessions *SessionTracker) *Metrics {
+	return &Metrics{
+		sessions:      sessions,
+		inflight:      make(map[string]time.Time),
+		toolCalls:     make(map[string]uint64),
+		toolErrors:    make(map[string]uint64),
+		toolLatency:   make(map[string]*histogram),
+		promptGets:    make(map[string]uint64),
+		resourceReads: make(map[string]uint64),
+		requestErrors: make(map[string]uint64),
+		responseBytes: make(map[string]uint64),
+		templates:     make(map[string]mcp.ResourceTemplate),
+	}
+}
+
+// Register Attaches the collector to the request hooks of a server
+func (m *Metrics) Register(hooks *server.Hooks) {
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.inflight[requestKey(ctx, id)] = time.Now()
+	})
+
+	hooks.AddAfterCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest, result any) {
+		toolResult, _ := result.(*mcp.CallToolResult)
+		m.finishToolCall(ctx, id, message.Params.Name, toolResult == nil || toolResult.IsError)
+	})
+
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		m.mu.Lock()
+		m.requestErrors[string(method)]++
+		m.mu.Unlock()
+
+		if request, ok := message.(*mcp.CallToolRequest); ok {
+			m.finishToolCall(ctx, id, request.Params.Name, true)
+		}
+	})
+
+	hooks.AddAfterGetPrompt(func(ctx context.Context, id any, message *mcp.GetPromptRequest, result *mcp.GetPromptResult) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.promptGets[message.Params.Name]++
+	})
+
+	hooks.AddAfterReadResource(func(ctx context.Context, id any, message *mcp.ReadResourceRequest, result *mcp.ReadResourceResult) {
+		name := m.resourceName(ctx, message.Params.URI)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.resourceReads[name]++
+	})
+}
+
+// AddResourceTemplates Records resource templates registered on the server, so that reads of their resources are counted by template name
+func (m *Metrics) AddResourceTemplates(templates ...server.ServerResourceTemplate) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, template := range templates {
+		if template.Template.URITemplate != nil {
+			m.templates[template.Template.URITemplate.Raw()] = template.Template
+		}
+	}
+}
+
+// SetResourceTemplates Records the resource templates of the server in place of those recorded so far, after the server's set was replaced
+func (m *Metrics) SetResourceTemplates(templates ...server.ServerResourceTemplate) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.templates = make(map[string]mcp.ResourceTemplate, len(templates))
+	m.mu.Unlock()
+	m.AddResourceTemplates(templates...)
+}
+
+// resourceName Name of the resource or template a URI was read from, so that the label takes one value per registered resource rather than per URI.
+//
+// A URI that neither names a resource nor matches a recorded template is
+// counted by its scheme.
+func (m *Metrics) resourceName(ctx context.Context, uri string) string {
+	if mcpServer := server.ServerFromContext(ctx); mcpServer != nil {
+		if resource, exists := mcpServer.ListResources()[uri]; exists {
+			return resource.Resource.Name
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range sortedKeys(m.templates) {
+		if template := m.templates[key]; template.URITemplate.Regexp().MatchString(uri) {
+			return template.Name
+		}
+	}
+	if scheme, _, found := strings.Cut(uri, "://"); found {
+		return scheme + "://"
+	}
+	return "other"
+}
+
+// addResponseBytes Counts bytes a transport wrote to its clients
+func (m *Metrics) addResponseBytes(transport string, n int) {
+	if m == nil || n <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responseBytes[transport] += uint64(n)
+}
+
+// finishToolCall Records the outcome and latency of a tool call started in the before hook
+func (m *Metrics) finishToolCall(ctx context.Context, id any, tool string, failed bool) {
+	key := requestKey(ctx, id)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start, ok := m.inflight[key]
+	if !ok {
+		return
+	}
+	delete(m.inflight, key)
+
+	m.toolCalls[tool]++
+	if failed {
+		m.toolErrors[tool]++
+	}
+	if m.toolLatency[tool] == nil {
+		m.toolLatency[tool] = &histogram{}
+	}
+	m.toolLatency[tool].observe(time.Since(start).Seconds())
+}
+
+// requestKey Identifies an in-flight request across concurrent sessions
+func requestKey(ctx context.Context, id any) string {
+	sessionID := ""
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+	return fmt.Sprintf("%s/%v", sessionID, id)
+}
+
+// WritePrometheus Writes all metrics in the Prometheus text exposition format
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	var b strings.Builder
+
+	m.mu.Lock()
+	writeCounter(&b, "mcp_tool_calls_total", "Total tool calls by tool.", "tool", m.toolCalls)
+	writeCounter(&b, "mcp_tool_errors_total", "Tool calls that failed or returned an error result, by tool.", "tool", m.toolErrors)
+	writeHistograms(&b, "mcp_tool_call_duration_seconds", "Tool call latency in seconds, by tool.", "tool", m.toolLatency)
+	writeCounter(&b, "mcp_prompt_gets_total", "Total prompts/get requests by prompt.", "prompt", m.promptGets)
+	writeCounter(&b, "mcp_resource_reads_total", "Total resources/read requests by resource or template name.", "resource", m.resourceReads)
+	writeCounter(&b, "mcp_request_errors_total", "JSON-RPC requests that returned an error, by method.", "method", m.requestErrors)
+	writeCounter(&b, "mcp_response_bytes_total", "Bytes written to clients, including JSON-RPC messages and stream events, by transport.", "transport", m.responseBytes)
+	m.mu.Unlock()
+
+	active := make(map[string]uint64)
+	for transport, count := range m.sessions.ActiveSessions() {
+		active[transport] = uint64(count)
+	}
+	writeMetric(&b, "mcp_active_sessions", "gauge", "Active client sessions by transport.", "transport", active)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler HTTP handler serving the metrics for Prometheus scrapes
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", prometheusContentType)
+		if err := m.WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// countingWriter Writer counting the bytes a transport writes to its clients
+type countingWriter struct {
+	io.Writer
+	metrics   *Metrics
+	transport string
+}
+
+// Write Writes p and counts the bytes written
+func (w countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.metrics.addResponseBytes(w.transport, n)
+	return n, err
+}
+
+// CountWriter Writer for the stdio transport counting the bytes written in the metrics, if any
+func (m *Metrics) CountWriter(w io.Writer, transport string) io.Writer {
+	return countingWriter{Writer: w, metrics: m, transport: transport}
+}
+
+// countingResponseWriter Response writer counting the bytes of responses and stream events
+type countingResponseWriter struct {
+	http.ResponseWriter
+	metrics   *Metrics
+	transport string
+}
+
+// Write Writes p and counts the bytes written
+func (w countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.metrics.addResponseBytes(w.transport, n)
+	return n, err
+}
+
+// Flush Flushes the underlying writer, which the SSE streams rely on
+func (w countingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap Underlying writer, for http.ResponseController
+func (w countingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// CountHandler Handler for the HTTP transports counting the bytes of its responses in the metrics, if any
+func (m *Metrics) CountHandler(handler http.Handler, transport string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(countingResponseWriter{ResponseWriter: w, metrics: m, transport: transport}, r)
+	})
+}
+
+// MetricsResource Metrics resource exposing the Prometheus exposition to clients without HTTP access
+func MetricsResource(metrics *Metrics) server.ServerResource {
+	resource := mcp.NewResource(
+		"metrics://prometheus",
+		"Server Metrics",
+		mcp.WithResourceDescription("Tool, prompt, resource, session and traffic metrics in Prometheus text exposition format"),
+		mcp.WithMIMEType(prometheusContentType),
+	)
+
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		var b strings.Builder
+		if err := metrics.WritePrometheus(&b); err != nil {
+			return nil, fmt.Errorf("failed to render metrics: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: prometheusContentType,
+				Text:     b.String(),
+			},
+		}, nil
+	}
+
+	return server.ServerResource{
+		Resource: resource,
+		Handler:  handler,
+	}
+}
+
+// writeCounter Writes a labelled counter family
+func writeCounter(b *strings.Builder, name, help, label string, values map[string]uint64) {
+	writeMetric(b, name, "counter", help, label, values)
+}
+
+// writeMetric Writes a metric family with one sample per label value, in label order
+func writeMetric(b *strings.Builder, name, kind, help, label string, values map[string]uint64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s{%s=\"%s\"} %d\n", name, label, escapeLabel(key), values[key])
+	}
+}
+
+// writeHistograms Writes a labelled histogram family
+func writeHistograms(b *strings.Builder, name, help, label string, values map[string]*histogram) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, key := range sortedKeys(values) {
+		h := values[key]
+		value := escapeLabel(key)
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(b, "%s_bucket{%s=\"%s\",le=\"%s\"} %d\n",
+				name, label, value, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%s=\"%s\",le=\"+Inf\"} %d\n", name, label, value, h.count)
+		fmt.Fprintf(b, "%s_sum{%s=\"%s\"} %s\n", name, label, value, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(b, "%s_count{%s=\"%s\"} %d\n", name, label, value, h.count)
+	}
+}
+
+// sortedKeys Map keys in ascending order for stable output
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapeLabel Escapes a label value per the exposition format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
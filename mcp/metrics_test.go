@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestMetricsResourceReads(t *testing.T) {
+	hooks := &server.Hooks{}
+	metrics := NewMetrics(NewSessionTracker())
+	metrics.Register(hooks)
+
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithHooks(hooks), server.WithResourceCapabilities(false, true))
+	mcpServer.AddResources(MathConstantsResource())
+	template := MathConstantTemplate(NewCompletions())
+	mcpServer.AddResourceTemplates(template)
+	metrics.AddResourceTemplates(template)
+
+	for _, uri := range []string{"math://constants", "math://constants/pi", "math://constants/e", "math://constants/tau"} {
+		request, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": map[string]string{"uri": uri}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mcpServer.HandleMessage(context.Background(), request)
+	}
+
+	var b strings.Builder
+	if err := metrics.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`mcp_resource_reads_total{resource="Mathematical Constant"} 2`,
+		`mcp_resource_reads_total{resource="Mathematical Constants"} 1`,
+		`mcp_request_errors_total{method="resources/read"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "math://constants/pi") {
+		t.Errorf("metrics are labelled by URI:\n%s", b.String())
+	}
+}
+
+func TestMetricsResourceName(t *testing.T) {
+	metrics := NewMetrics(NewSessionTracker())
+	metrics.AddResourceTemplates(MathConstantTemplate(NewCompletions()))
+	metrics.AddResourceTemplates(PromptPreviewTemplates(NewCompletions(), mcp.NewPrompt("math_tutor", mcp.WithArgument("topic")))...)
+
+	tests := map[string]string{
+		"math://constants/pi":            "Mathematical Constant",
+		"prompt://math_tutor?topic=sets": "Prompt Preview: math_tutor",
+		"prompt://math_tutor_v2":         "prompt://",
+		"file:///etc/hosts":              "file://",
+		"urn:isbn:0451450523":            "other",
+	}
+	for uri, want := range tests {
+		if got := metrics.resourceName(context.Background(), uri); got != want {
+			t.Errorf("resourceName(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
+
+func TestMetricsCountHandler(t *testing.T) {
+	metrics := NewMetrics(NewSessionTracker())
+	handler := metrics.CountHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{}}\n\n")
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("response writer is not a flusher")
+		}
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("flush: %v", err)
+		}
+	}), "sse")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !recorder.Flushed {
+		t.Error("response was not flushed")
+	}
+	if got, want := metrics.responseBytes["sse"], uint64(recorder.Body.Len()); got != want {
+		t.Errorf("counted %d response bytes, want %d", got, want)
+	}
+
+	var stdout strings.Builder
+	io.WriteString(metrics.CountWriter(&stdout, "stdio"), "{}\n")
+	if got := metrics.responseBytes["stdio"]; got != 3 {
+		t.Errorf("counted %d stdio bytes, want 3", got)
+	}
+}
@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+//go:embed data/physical_constants.json
+var physicalConstantsData []byte
+
+// physicalConstant A single CODATA constant with its SI unit and standard uncertainty
+type physicalConstant struct {
+	Name        string  `json:"name"`
+	Quantity    string  `json:"quantity"`
+	Symbol      string  `json:"symbol"`
+	Category    string  `json:"category"`
+	Value       float64 `json:"value"`
+	Unit        string  `json:"unit"`
+	Uncertainty float64 `json:"uncertainty"`
+	Exact       bool    `json:"exact"`
+	Source      string  `json:"source"`
+}
+
+// physicalConstantsCatalog Embedded dataset of physical constants and its citation
+type physicalConstantsCatalog struct {
+	Source struct {
+		Name     string `json:"name"`
+		Citation string `json:"citation"`
+		URL      string `json:"url"`
+	} `json:"source"`
+	Constants []physicalConstant `json:"constants"`
+}
+
+// categories Sorted, de-duplicated list of constant categories
+func (c *physicalConstantsCatalog) categories() []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, constant := range c.Constants {
+		if !seen[constant.Category] {
+			seen[constant.Category] = true
+			categories = append(categories, constant.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// loadPhysicalConstants Parses the embedded dataset once
+var loadPhysicalConstants = sync.OnceValues(func() (*physicalConstantsCatalog, error) {
+	var catalog physicalConstantsCatalog
+	if err := json.Unmarshal(physicalConstantsData, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse physical constants dataset: %w", err)
+	}
+	return &catalog, nil
+})
+
+// PhysicalConstantsResource Physical constants resource with the full CODATA catalog
+func PhysicalConstantsResource() server.ServerResource {
+	resource := mcp.NewResource(
+		"physics://constants",
+		"Physical Constants",
+		mcp.WithResourceDescription("CODATA 2018 fundamental physical constants with SI units, standard uncertainties and source citations"),
+		mcp.WithMIMEType("application/json"),
+	)
+
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		catalog, err := loadPhysicalConstants()
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := json.MarshalIndent(map[string]interface{}{
+			"source":     catalog.Source,
+			"categories": catalog.categories(),
+			"constants":  catalog.Constants,
+		}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal physical constants: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(content),
+			},
+		}, nil
+	}
+
+	return server.ServerResource{
+		Resource: resource,
+		Handler:  handler,
+	}
+}
+
+// PhysicalConstantsTemplate Physical constants resource template filtering the catalog by category
+func PhysicalConstantsTemplate(completions *Completions) server.ServerResourceTemplate {
+	const uriTemplate = "physics://constants/{category}"
+
+	template := mcp.NewResourceTemplate(
+		uriTemplate,
+		"Physical Constants by Category",
+		mcp.WithTemplateDescription("CODATA 2018 physical constants in one category (universal, electromagnetic, atomic_nuclear, physico_chemical, adopted)"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	completions.AddResourceArgument(uriTemplate, "category", func(ctx context.Context, value string, resolved map[string]string) []string {
+		catalog, err := loadPhysicalConstants()
+		if err != nil {
+			return nil
+		}
+		return filterPrefix(catalog.categories(), value)
+	})
+
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		catalog, err := loadPhysicalConstants()
+		if err != nil {
+			return nil, err
+		}
+
+		category := templateArgument(request, "category")
+
+		var constants []physicalConstant
+		for _, constant := range catalog.Constants {
+			if constant.Category == category {
+				constants = append(constants, constant)
+			}
+		}
+		if len(constants) == 0 {
+			return nil, fmt.Errorf("unknown category %q, valid categories are %s: %w",
+				category, strings.Join(catalog.categories(), ", "), server.ErrResourceNotFound)
+		}
+
+		content, err := json.MarshalIndent(map[string]interface{}{
+			"source":    catalog.Source,
+			"category":  category,
+			"constants": constants,
+		}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal physical constants: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(content),
+			},
+		}, nil
+	}
+
+	return server.ServerResourceTemplate{
+		Template: template,
+		Handler:  handler,
+	}
+}
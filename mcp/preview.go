@@ -0,0 +1,204 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// previewScheme URI scheme of prompt previews, e.g. prompt://math_tutor?topic=algebra
+const previewScheme = "prompt://"
+
+// previewFormatArgument Query parameter selecting the output of a preview
+const previewFormatArgument = "format"
+
+// previewTemplateGroup Group of the preview templates in a ResourceTemplates registry
+const previewTemplateGroup = "prompt_previews"
+
+// Output formats of a prompt preview
+const (
+	// previewMarkdown The messages as markdown sections, the default
+	previewMarkdown = "markdown"
+	// previewJSON The prompts/get result as JSON
+	previewJSON = "json"
+)
+
+// previewVariablePattern Argument names usable as URI template variables
+var previewVariablePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// PromptPreviewTemplates One resource template per prompt, rendering it with the arguments in the query as markdown or JSON.
+//
+// Previews go through the handler registered on the server, so they show
+// exactly what prompts/get returns, including validation errors.
+func PromptPreviewTemplates(completions *Completions, prompts ...mcp.Prompt) []server.ServerResourceTemplate {
+	templates := make([]server.ServerResourceTemplate, 0, len(prompts))
+	for _, prompt := range prompts {
+		name := prompt.Name
+
+		variables := []string{}
+		argumentNames := []string{}
+		for _, argument := range prompt.Arguments {
+			argumentNames = append(argumentNames, argument.Name)
+			if previewVariablePattern.MatchString(argument.Name) {
+				variables = append(variables, argument.Name)
+			}
+		}
+		variables = append(variables, previewFormatArgument)
+		uriTemplate := fmt.Sprintf("%s%s{?%s}", previewScheme, url.PathEscape(name), strings.Join(variables, ","))
+
+		template := mcp.NewResourceTemplate(
+			uriTemplate,
+			"Prompt Preview: "+name,
+			mcp.WithTemplateDescription(fmt.Sprintf("Messages of the %s prompt for the arguments in the query (%s), as markdown or, with format=json, the prompts/get result",
+				name, strings.Join(argumentNames, ", "))),
+			mcp.WithTemplateMIMEType(markdownMIMEType),
+		)
+
+		// Arguments complete like the prompt's own, looked up per request so that reloads apply
+		for _, argument := range prompt.Arguments {
+			completions.AddResourceArgument(uriTemplate, argument.Name, func(ctx context.Context, value string, resolved map[string]string) []string {
+				if fn := completions.promptArgument(name, argument.Name); fn != nil {
+					return fn(ctx, value, resolved)
+				}
+				return nil
+			})
+		}
+		completions.AddResourceArgument(uriTemplate, previewFormatArgument, PrefixCompletion(previewMarkdown, previewJSON))
+
+		templates = append(templates, server.ServerResourceTemplate{
+			Template: template,
+			Handler:  previewPrompt(name),
+		})
+	}
+	return templates
+}
+
+// previewPrompt Handler rendering the named prompt through its handler on the server, with the arguments the server extracted from the URI through the preview template.
+//
+// The server passes no arguments when the query does not fit the template,
+// i.e. when it names other parameters, repeats one or lists them out of
+// order, so such a query is rejected rather than rendering the prompt without
+// arguments.
+func previewPrompt(name string) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		uri := request.Params.URI
+		args := make(map[string]string, len(request.Params.Arguments))
+		for argument, value := range request.Params.Arguments {
+			switch value := value.(type) {
+			case string:
+				args[argument] = value
+			case []string:
+				if len(value) > 1 {
+					return nil, fmt.Errorf("argument %q is given %d times in %s: %w", argument, len(value), uri, mcp.ErrInvalidParams)
+				}
+				if len(value) == 1 {
+					args[argument] = value[0]
+				}
+			}
+		}
+		if _, query, _ := strings.Cut(uri, "?"); query != "" && len(args) == 0 {
+			return nil, fmt.Errorf("query of %s does not fit the preview template: give only the prompt's arguments and format, each once and in the template's order: %w", uri, mcp.ErrInvalidParams)
+		}
+
+		format := previewMarkdown
+		if value, exists := args[previewFormatArgument]; exists {
+			format = strings.ToLower(value)
+			delete(args, previewFormatArgument)
+		}
+		if format != previewMarkdown && format != previewJSON {
+			return nil, fmt.Errorf("unknown preview format %q, expected %s or %s: %w", format, previewMarkdown, previewJSON, mcp.ErrInvalidParams)
+		}
+
+		return renderPreview(ctx, uri, name, format, args)
+	}
+}
+
+// renderPreview Prompt result for the arguments as a resource in the format
+func renderPreview(ctx context.Context, uri, name, format string, args map[string]string) ([]mcp.ResourceContents, error) {
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil, errors.New("prompt previews need a server in the request context")
+	}
+	prompt, exists := mcpServer.ListPrompts()[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown prompt %q: %w", name, server.ErrResourceNotFound)
+	}
+
+	getRequest := mcp.GetPromptRequest{}
+	getRequest.Params.Name = name
+	getRequest.Params.Arguments = args
+	result, err := prompt.Handler(ctx, getRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == previewJSON {
+		content, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal prompt %s: %w", name, err)
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(content),
+			},
+		}, nil
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: markdownMIMEType,
+			Text:     previewMarkdownText(name, result),
+		},
+	}, nil
+}
+
+// previewMarkdownText Prompt result as a markdown document with one section per message
+func previewMarkdownText(name string, result *mcp.GetPromptResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n", name, result.Description)
+
+	for i, message := range result.Messages {
+		fmt.Fprintf(&b, "\n## %d. %s\n\n", i+1, message.Role)
+		switch content := message.Content.(type) {
+		case mcp.TextContent:
+			b.WriteString(strings.TrimRight(content.Text, "\n"))
+			b.WriteString("\n")
+		case mcp.EmbeddedResource:
+			switch resource := content.Resource.(type) {
+			case mcp.TextResourceContents:
+				fmt.Fprintf(&b, "Embedded resource `%s` (%s):\n\n", resource.URI, resource.MIMEType)
+				fence := codeFence(resource.Text)
+				fmt.Fprintf(&b, "%s\n%s\n%s\n", fence, strings.TrimRight(resource.Text, "\n"), fence)
+			case mcp.BlobResourceContents:
+				fmt.Fprintf(&b, "Embedded resource `%s` (%s, %d bytes base64)\n", resource.URI, resource.MIMEType, len(resource.Blob))
+			}
+		default:
+			fmt.Fprintf(&b, "%T content\n", content)
+		}
+	}
+	return b.String()
+}
+
+// codeFence Backtick fence longer than any run of backticks in text, so that the text cannot close it
+func codeFence(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPromptPreview(t *testing.T) {
+	mcpServer := newPromptTestServer(t)
+
+	tests := []struct {
+		name     string
+		uri      string
+		mimeType string
+		contains []string
+	}{
+		{
+			name:     "markdown",
+			uri:      "prompt://math_tutor?topic=linear%20algebra&level=college",
+			mimeType: markdownMIMEType,
+			contains: []string{"# math_tutor", "linear algebra", "undergraduate level"},
+		},
+		{
+			name:     "json",
+			uri:      "prompt://math_tutor?topic=sets&format=JSON",
+			mimeType: "application/json",
+			contains: []string{`"messages"`, "sets"},
+		},
+		{
+			name:     "translated examples",
+			uri:      "prompt://math_tutor@v1?topic=calculus&level=hs&locale=es",
+			mimeType: markdownMIMEType,
+			contains: []string{"Eres un tutor", "¿Cómo calculo la derivada"},
+		},
+		{
+			name:     "no query",
+			uri:      "prompt://code_review",
+			mimeType: markdownMIMEType,
+			contains: []string{"# code_review"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": map[string]any{"uri": tt.uri}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, err := json.Marshal(mcpServer.HandleMessage(context.Background(), request))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var response struct {
+				Result struct {
+					Contents []mcp.TextResourceContents `json:"contents"`
+				} `json:"result"`
+				Error *mcp.JSONRPCErrorDetails `json:"error"`
+			}
+			if err := json.Unmarshal(encoded, &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Error != nil || len(response.Result.Contents) != 1 {
+				t.Fatalf("response %s is not one resource", encoded)
+			}
+			content := response.Result.Contents[0]
+			if content.URI != tt.uri || content.MIMEType != tt.mimeType {
+				t.Errorf("content is %s as %s, want %s as %s", content.URI, content.MIMEType, tt.uri, tt.mimeType)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(content.Text, want) {
+					t.Errorf("preview does not contain %q:\n%s", want, content.Text)
+				}
+			}
+		})
+	}
+}
@@ -1,229 +1,691 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
+	"embed"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
 )
 
-// MathTutorPrompt Math tutor prompt for helping with mathematical concepts
-func MathTutorPrompt() server.ServerPrompt {
-	prompt := mcp.NewPrompt("math_tutor",
-		mcp.WithPromptDescription("A comprehensive math tutor that provides detailed explanations, step-by-step solutions, and interactive learning experiences"),
-		mcp.WithArgument("topic",
-			mcp.ArgumentDescription("The specific math topic to focus on (e.g., algebra, calculus, geometry, statistics, trigonometry, linear algebra, differential equations)"),
-		),
-		mcp.WithArgument("level",
-			mcp.ArgumentDescription("The difficulty level and educational context (elementary, middle school, high school, undergraduate, graduate, professional)"),
-		),
-		mcp.WithArgument("learning_style",
-			mcp.ArgumentDescription("Preferred learning approach (visual, analytical, practical, conceptual, problem-solving focused)"),
-		),
-	)
-
-	handler := func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-		topic := "general mathematics"
-		if t, exists := request.Params.Arguments["topic"]; exists && t != "" {
-			topic = t
-		}
-
-		level := "intermediate"
-		if l, exists := request.Params.Arguments["level"]; exists && l != "" {
-			level = l
-		}
-
-		learningStyle := "balanced"
-		if ls, exists := request.Params.Arguments["learning_style"]; exists && ls != "" {
-			learningStyle = ls
-		}
-
-		elaboratePrompt := fmt.Sprintf(`You are an expert mathematics tutor specializing in %s at the %s level, with a %s teaching approach. Your role is to:
-
-**TEACHING METHODOLOGY:**
-- Break down complex concepts into digestible, logical steps
-- Provide multiple solution approaches when applicable
-- Use real-world analogies and examples to illustrate abstract concepts
-- Encourage critical thinking through guided questions
-- Adapt explanations based on student understanding
-
-**PROBLEM-SOLVING APPROACH:**
-1. **Understanding**: Ensure complete comprehension of the problem
-2. **Strategy**: Identify the most appropriate method(s)
-3. **Execution**: Work through solutions step-by-step
-4. **Verification**: Check answers and explore alternative approaches
-5. **Application**: Connect to broader mathematical concepts
-
-**COMMUNICATION STYLE:**
-- Use clear, precise mathematical language
-- Provide visual representations when helpful (describe diagrams, graphs, charts)
-- Include common mistakes to avoid
-- Offer practice problems with varying difficulty
-- Give constructive feedback and encouragement
-
-**SPECIFIC FOCUS FOR %s:**
-- Fundamental principles and theorems
-- Key formulas and when to apply them
-- Problem-solving patterns and techniques
-- Connections to other mathematical areas
-- Practical applications and relevance
-
-**INTERACTION GUIDELINES:**
-- Ask clarifying questions when problems are ambiguous
-- Provide hints before full solutions when appropriate
-- Explain the 'why' behind mathematical procedures
-- Offer additional resources for deeper understanding
-- Maintain patience and positive reinforcement
-
-Please share your mathematical question, problem, or concept you'd like to explore. I'll provide comprehensive guidance tailored to your %s level understanding with a %s learning approach.`,
-			topic, level, learningStyle, topic, level, learningStyle)
-
-		messages := []mcp.PromptMessage{
-			mcp.NewPromptMessage(
-				mcp.RoleUser,
-				mcp.NewTextContent(elaboratePrompt),
-			),
-		}
-
-		return mcp.NewGetPromptResult(
-			fmt.Sprintf("Comprehensive Math Tutoring: %s (%s level, %s approach)", topic, level, learningStyle),
-			messages,
-		), nil
-	}
-
-	return server.ServerPrompt{
-		Prompt:  prompt,
-		Handler: handler,
-	}
-}
-
-// CodeReviewPrompt Code review prompt for providing feedback on code
-func CodeReviewPrompt() server.ServerPrompt {
-	prompt := mcp.NewPrompt("code_review",
-		mcp.WithPromptDescription("A comprehensive code reviewer that provides detailed analysis, suggestions, and best practices guidance"),
-		mcp.WithArgument("language",
-			mcp.ArgumentDescription("The programming language or technology stack (e.g., Python, JavaScript, Go, Java, C++, React, Django)"),
-		),
-		mcp.WithArgument("focus",
-			mcp.ArgumentDescription("Primary review focus areas (performance, security, readability, architecture, testing, maintainability, scalability)"),
-		),
-		mcp.WithArgument("experience_level",
-			mcp.ArgumentDescription("Target developer experience level (junior, mid-level, senior, lead, architect)"),
-		),
-		mcp.WithArgument("review_type",
-			mcp.ArgumentDescription("Type of review (pre-commit, post-implementation, refactoring, security audit, performance optimization)"),
-		),
-	)
-
-	handler := func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-		language := "general programming"
-		if l, exists := request.Params.Arguments["language"]; exists && l != "" {
-			language = l
-		}
-
-		focus := "comprehensive quality"
-		if f, exists := request.Params.Arguments["focus"]; exists && f != "" {
-			focus = f
-		}
-
-		experienceLevel := "mid-level"
-		if el, exists := request.Params.Arguments["experience_level"]; exists && el != "" {
-			experienceLevel = el
-		}
-
-		reviewType := "general review"
-		if rt, exists := request.Params.Arguments["review_type"]; exists && rt != "" {
-			reviewType = rt
-		}
-
-		elaboratePrompt := fmt.Sprintf(`You are a senior software engineer and code review expert specializing in %s, conducting a %s focused on %s for a %s developer. Your comprehensive review should cover:
-
-**CODE QUALITY ASSESSMENT:**
-1. **Functionality & Logic**
-   - Correctness of implementation
-   - Edge case handling
-   - Error handling and recovery
-   - Input validation and sanitization
-
-2. **Code Structure & Design**
-   - Adherence to SOLID principles
-   - Design patterns usage
-   - Separation of concerns
-   - Modularity and reusability
-
-3. **Performance & Efficiency**
-   - Algorithm complexity analysis
-   - Memory usage optimization
-   - Database query efficiency
-   - Caching strategies
-
-4. **Security Considerations**
-   - Vulnerability identification
-   - Authentication and authorization
-   - Data encryption and protection
-   - Secure coding practices
-
-5. **Maintainability & Readability**
-   - Code clarity and self-documentation
-   - Naming conventions
-   - Comment quality and necessity
-   - Code organization and structure
-
-**%s SPECIFIC GUIDELINES:**
-- Language-specific best practices
-- Framework/library conventions
-- Performance characteristics
-- Common pitfalls and anti-patterns
-- Ecosystem-specific tools and utilities
-
-**REVIEW METHODOLOGY:**
-**POSITIVE FEEDBACK:**
-- Highlight well-implemented sections
-- Acknowledge good practices
-- Recognize creative solutions
-
-**CONSTRUCTIVE CRITICISM:**
-- Specific, actionable suggestions
-- Code examples for improvements
-- Explanation of reasoning behind recommendations
-- Alternative implementation approaches
-
-**PRIORITY CLASSIFICATION:**
-- 🔴 Critical: Security issues, bugs, breaking changes
-- 🟡 Important: Performance, maintainability concerns  
-- 🔵 Nice-to-have: Style improvements, minor optimizations
-
-**DOCUMENTATION & TESTING:**
-- Test coverage adequacy
-- Documentation completeness
-- API documentation quality
-- Inline comment appropriateness
-
-**COLLABORATION NOTES:**
-- Learning opportunities for the developer
-- Knowledge sharing suggestions
-- Team standards alignment
-- Future improvement recommendations
-
-Please provide the code you'd like reviewed, and I'll deliver a thorough analysis appropriate for a %s developer, focusing on %s aspects in this %s context.`,
-			language, reviewType, focus, experienceLevel, language, experienceLevel, focus, reviewType)
-
-		messages := []mcp.PromptMessage{
-			mcp.NewPromptMessage(
-				mcp.RoleAssistant,
-				mcp.NewTextContent(elaboratePrompt),
-			),
-		}
+//go:embed data/prompts
+var promptFiles embed.FS
 
-		return mcp.NewGetPromptResult(
-			fmt.Sprintf("Comprehensive Code Review: %s (%s focus, %s level, %s)", language, focus, experienceLevel, reviewType),
-			messages,
-		), nil
+// promptExtensions File extensions recognised as prompt definitions
+var promptExtensions = []string{".md", ".yaml", ".yml"}
+
+// promptFuncs Functions available to prompt templates
+var promptFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"slug":  slug,
+	// term translates a curated value in translated templates; the prompt files themselves use it as is
+	"term": func(value string) string { return value },
+}
+
+// BuiltinPrompts Prompt definitions shipped with the server
+func BuiltinPrompts() fs.FS {
+	prompts, err := fs.Sub(promptFiles, "data/prompts")
+	if err != nil {
+		panic(err)
+	}
+	return prompts
+}
+
+// promptArgument A declared prompt argument.
+//
+// Values are the curated values, offered as completions and used to
+// normalize input; Strict rejects anything else. ValuesBy narrows the
+// completions by the value already chosen for an earlier argument, keyed by
+// argument name and then by that argument's value. Synonyms map alternative
+// spellings to canonical values. Format "diff" parses the value as a unified
+// diff, attaches each changed file and hands the template a summary instead;
+// format "stacktrace" does the same with the top frames of a stack trace and
+// format "go" with a Go file and its table-driven test scaffold.
+type promptArgument struct {
+	Name        string                         `yaml:"name"`
+	Description string                         `yaml:"description"`
+	Default     string                         `yaml:"default"`
+	Required    bool                           `yaml:"required"`
+	Values      []string                       `yaml:"values"`
+	ValuesBy    map[string]map[string][]string `yaml:"values_by"`
+	Strict      bool                           `yaml:"strict"`
+	Synonyms    map[string]string              `yaml:"synonyms"`
+	MaxLength   int                            `yaml:"max_length"`
+	Format      string                         `yaml:"format"`
+}
+
+// suggestions Curated values for the argument given the arguments resolved so far
+func (a promptArgument) suggestions(resolved map[string]string) []string {
+	for _, dependency := range sortedKeys(a.ValuesBy) {
+		chosen := slug(resolved[dependency])
+		if chosen == "" {
+			continue
+		}
+		for value, suggestions := range a.ValuesBy[dependency] {
+			if slug(value) == chosen {
+				return suggestions
+			}
+		}
+	}
+	return a.Values
+}
+
+// promptSpec A prompt definition as written in a prompt file.
+//
+// Markdown files carry the spec as YAML front matter and the template as the
+// document body; YAML files carry the template in the template field.
+type promptSpec struct {
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description"`
+	Title       string           `yaml:"title"`
+	Version     string           `yaml:"version"`
+	Role        string           `yaml:"role"`
+	Arguments   []promptArgument `yaml:"arguments"`
+	Resources   []string         `yaml:"resources"`
+	Examples    []promptExample  `yaml:"examples"`
+	Template    string           `yaml:"template"`
+}
+
+// promptDefinition A prompt file with its compiled templates
+type promptDefinition struct {
+	spec         promptSpec
+	file         string
+	role         mcp.Role
+	title        *template.Template
+	body         *template.Template
+	resources    []*template.Template
+	examples     []compiledExample
+	translations map[string]*translation
+	policy       *ArgumentPolicy
+}
+
+// PromptLibrary Prompt library built from prompt definition files.
+//
+// Prompts are keyed by name, or by name@version for versioned prompts, whose
+// unversioned name is an alias resolved by the configured variants.
+type PromptLibrary struct {
+	prompts  map[string]*promptDefinition
+	names    []string
+	versions map[string][]string
+	variants map[string][]promptVariant
+	audit    *slog.Logger
+}
+
+// NewPromptLibrary Loads the top-level prompt files of each source; later sources override earlier ones with the same prompt name
+func NewPromptLibrary(sources ...fs.FS) (*PromptLibrary, error) {
+	library := &PromptLibrary{
+		prompts:  make(map[string]*promptDefinition),
+		versions: make(map[string][]string),
+		variants: make(map[string][]promptVariant),
+	}
+
+	for _, source := range sources {
+		defined := make(map[string]string)
+		versioned := make(map[string]bool)
+
+		for _, ext := range promptExtensions {
+			matches, err := fs.Glob(source, "*"+ext)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list prompts: %w", err)
+			}
+
+			for _, match := range matches {
+				if isExampleFile(match) {
+					continue
+				}
+
+				data, err := fs.ReadFile(source, match)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read prompt %s: %w", match, err)
+				}
+
+				definition, err := parsePromptFile(match, data)
+				if err != nil {
+					return nil, err
+				}
+
+				name, key := definition.spec.Name, definition.spec.key()
+				if previous, exists := defined[key]; exists {
+					return nil, fmt.Errorf("%s: prompt %q is already defined in %s", match, key, previous)
+				}
+				if isVersioned, exists := versioned[name]; exists && isVersioned != (definition.spec.Version != "") {
+					return nil, fmt.Errorf("%s: prompt %q is defined both with and without a version", match, name)
+				}
+				// An unversioned prompt replaces every version from earlier sources, a version replaces the unversioned prompt
+				if definition.spec.Version == "" {
+					library.remove(name)
+				} else {
+					delete(library.prompts, name)
+				}
+				defined[key] = match
+				versioned[name] = definition.spec.Version != ""
+				library.prompts[key] = definition
+			}
+		}
+
+		// Translations attach to the definitions current after this source, so overriding a prompt drops the old translations
+		if err := library.loadTranslations(source); err != nil {
+			return nil, err
+		}
+	}
+
+	// Example files may extend prompts of any source, so they load once every prompt is known
+	for _, source := range sources {
+		if err := library.loadExampleFiles(source); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, definition := range library.prompts {
+		library.names = append(library.names, key)
+		if definition.spec.Version != "" {
+			library.versions[definition.spec.Name] = append(library.versions[definition.spec.Name], definition.spec.Version)
+		}
+		definition.updateLocales()
+	}
+	sort.Strings(library.names)
+	for _, versions := range library.versions {
+		sortVersions(versions)
+	}
+	if err := library.checkVersionArguments(); err != nil {
+		return nil, err
+	}
+
+	return library, nil
+}
+
+// Names Sorted names of the loaded prompts, with name@version for versioned ones
+func (l *PromptLibrary) Names() []string {
+	return l.names
+}
+
+// Prompts One server prompt per loaded definition, plus an alias per versioned prompt
+func (l *PromptLibrary) Prompts() []server.ServerPrompt {
+	prompts := make([]server.ServerPrompt, 0, len(l.names)+len(l.versions))
+	for _, key := range l.names {
+		definition := l.prompts[key]
+		handler := definition.handle
+		if definition.spec.Version != "" {
+			handler = func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				return l.serve(ctx, request, definition, "explicit")
+			}
+		}
+		prompts = append(prompts, server.ServerPrompt{
+			Prompt:  definition.prompt(),
+			Handler: handler,
+		})
+	}
+	for _, name := range sortedKeys(l.versions) {
+		prompts = append(prompts, l.aliasPrompt(name))
+	}
+	return prompts
+}
+
+// RegisterCompletions Registers completion of every argument that declares curated values
+func (l *PromptLibrary) RegisterCompletions(completions *Completions) {
+	for _, key := range l.names {
+		for _, argument := range l.prompts[key].spec.Arguments {
+			if len(argument.Values) == 0 && len(argument.ValuesBy) == 0 {
+				continue
+			}
+			completions.AddPromptArgument(key, argument.Name, func(ctx context.Context, value string, resolved map[string]string) []string {
+				return filterPrefix(argument.suggestions(resolved), value)
+			})
+		}
+	}
+
+	// Aliases complete like the version they resolve to by default
+	for _, name := range sortedKeys(l.versions) {
+		for _, version := range l.versions[name] {
+			for _, argument := range l.prompts[name+"@"+version].spec.Arguments {
+				completions.AddPromptArgument(name, argument.Name, func(ctx context.Context, value string, resolved map[string]string) []string {
+					definition, _ := l.lookup(name)
+					for _, declared := range definition.spec.Arguments {
+						if declared.Name == argument.Name {
+							return filterPrefix(declared.suggestions(resolved), value)
+						}
+					}
+					return nil
+				})
+			}
+		}
+	}
+}
+
+// parsePromptFile Parses and compiles a prompt file, reporting errors with the file name and line
+func parsePromptFile(file string, data []byte) (*promptDefinition, error) {
+	content := string(data)
+	header := content
+	body := ""
+	bodyLine := 0
+
+	if path.Ext(file) == ".md" {
+		var err error
+		header, body, bodyLine, err = splitFrontMatter(file, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(header), &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	var spec promptSpec
+	if len(root.Content) > 0 {
+		decoder := yaml.NewDecoder(strings.NewReader(header))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	if path.Ext(file) == ".md" {
+		if spec.Template != "" {
+			return nil, fmt.Errorf("%s: markdown prompts take their template from the body, not the template field", file)
+		}
+		spec.Template = body
+	} else if node := mappingValue(&root, "template"); node != nil {
+		bodyLine = templateLine(node)
+	}
+
+	if spec.Name == "" {
+		spec.Name = strings.TrimSuffix(path.Base(file), path.Ext(file))
+	}
+	if strings.Contains(spec.Name, "@") {
+		return nil, fmt.Errorf("%s:%d: prompt name %q must not contain @, declare the version in the version field", file, fieldLine(&root, "name", 0), spec.Name)
+	}
+	if spec.Version != "" && !promptVersionPattern.MatchString(spec.Version) {
+		return nil, fmt.Errorf("%s:%d: invalid version %q, expected v followed by a number such as v2", file, fieldLine(&root, "version", 0), spec.Version)
+	}
+	if strings.TrimSpace(spec.Template) == "" {
+		return nil, fmt.Errorf("%s: prompt %q has an empty template", file, spec.Name)
+	}
+
+	definition := &promptDefinition{spec: spec, file: file}
+
+	role, err := parseRole(spec.Role)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%d: %w", file, fieldLine(&root, "role", 0), err)
+	}
+	definition.role = role
+
+	seen := make(map[string]bool)
+	for _, argument := range spec.Arguments {
+		if argument.Name == localeArgumentName {
+			return nil, fmt.Errorf("%s:%d: argument %q is reserved for the translation catalog", file, fieldLine(&root, "arguments", 0), argument.Name)
+		}
+		if argument.Name == previewFormatArgument {
+			return nil, fmt.Errorf("%s:%d: argument %q is reserved for prompt previews", file, fieldLine(&root, "arguments", 0), argument.Name)
+		}
+		if argument.Name == "" {
+			return nil, fmt.Errorf("%s:%d: prompt argument without a name", file, fieldLine(&root, "arguments", 0))
+		}
+		if seen[argument.Name] {
+			return nil, fmt.Errorf("%s:%d: duplicate prompt argument %q", file, fieldLine(&root, "arguments", 0), argument.Name)
+		}
+		seen[argument.Name] = true
+		if err := argument.check(); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, fieldLine(&root, "arguments", 0), err)
+		}
+	}
+	for _, argument := range spec.Arguments {
+		for dependency := range argument.ValuesBy {
+			if !seen[dependency] || dependency == argument.Name {
+				return nil, fmt.Errorf("%s:%d: argument %q takes values_by from %q, which is not another argument of the prompt",
+					file, fieldLine(&root, "arguments", 0), argument.Name, dependency)
+			}
+		}
+	}
+
+	// Every prompt takes a locale, so that templates and example conditions may refer to it
+	definition.spec.Arguments = append(definition.spec.Arguments, localeArgument())
+	seen[localeArgumentName] = true
+
+	if definition.body, err = compilePromptTemplate(file, bodyLine, spec.Template); err != nil {
+		return nil, err
+	}
+	if spec.Title != "" {
+		if definition.title, err = compilePromptTemplate(file, templateLine(mappingValue(&root, "title")), spec.Title); err != nil {
+			return nil, err
+		}
+	}
+	if node := mappingValue(&root, "resources"); node != nil {
+		for i, uri := range spec.Resources {
+			resource, err := compilePromptTemplate(file, templateLine(node.Content[i]), uri)
+			if err != nil {
+				return nil, err
+			}
+			definition.resources = append(definition.resources, resource)
+		}
+	}
+	if node := mappingValue(&root, "examples"); node != nil {
+		if definition.examples, err = compileExamples(file, node, spec.Examples, seen); err != nil {
+			return nil, err
+		}
+	}
+
+	// Render once with sample values so that unknown variables fail at startup rather than on first use
+	sample := definition.sampleArguments()
+	if _, err := definition.render(sample, nil); err != nil {
+		return nil, err
+	}
+	if err := checkExamples(definition.examples, sample); err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+// argumentNames Set of declared argument names
+func (d *promptDefinition) argumentNames() map[string]bool {
+	names := make(map[string]bool, len(d.spec.Arguments))
+	for _, argument := range d.spec.Arguments {
+		names[argument.Name] = true
+	}
+	return names
+}
+
+// sampleArguments Default of every argument, or its name when it has none
+func (d *promptDefinition) sampleArguments() map[string]string {
+	sample := make(map[string]string, len(d.spec.Arguments))
+	for _, argument := range d.spec.Arguments {
+		sample[argument.Name] = argument.Default
+		if sample[argument.Name] == "" {
+			sample[argument.Name] = argument.Name
+		}
+	}
+	return sample
+}
+
+// parseRole Message role from its name in a prompt file, defaulting to user
+func parseRole(name string) (mcp.Role, error) {
+	switch name {
+	case "", string(mcp.RoleUser):
+		return mcp.RoleUser, nil
+	case string(mcp.RoleAssistant):
+		return mcp.RoleAssistant, nil
+	default:
+		return "", fmt.Errorf("unknown role %q, expected user or assistant", name)
+	}
+}
+
+// splitFrontMatter Splits a markdown prompt into YAML front matter and body.
+//
+// The front matter keeps a leading blank line in place of the opening
+// delimiter so that YAML error lines match the file.
+func splitFrontMatter(file, content string) (string, string, int, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return "", content, 0, nil
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			header := "\n" + strings.Join(lines[1:i], "")
+			body := strings.Join(lines[i+1:], "")
+			return header, body, i + 1, nil
+		}
+	}
+
+	return "", "", 0, fmt.Errorf("%s:1: front matter is not closed with ---", file)
+}
+
+// mappingValue Value node of a key in a YAML mapping, or in the top-level mapping of a document
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	mapping := node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// fieldLine File line of a top-level key, or fallback when absent
+func fieldLine(root *yaml.Node, key string, fallback int) int {
+	if node := mappingValue(root, key); node != nil {
+		return node.Line
+	}
+	return fallback
+}
+
+// templateLine Number of file lines preceding the text of a scalar node
+func templateLine(node *yaml.Node) int {
+	if node == nil {
+		return 0
+	}
+	// Block scalars start on the line after their indicator
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return node.Line
+	}
+	return node.Line - 1
+}
+
+// compilePromptTemplate Parses a template that starts after the given number of file lines.
+//
+// Padding the text with that many newlines makes text/template report parse
+// and execution errors as file:line of the prompt file itself.
+func compilePromptTemplate(file string, line int, text string) (*template.Template, error) {
+	return compileTemplate(file, line, text, promptFuncs)
+}
+
+// compileTemplate Parses a prompt template with the given functions
+func compileTemplate(file string, line int, text string, funcs template.FuncMap) (*template.Template, error) {
+	return template.New(file).
+		Option("missingkey=error").
+		Funcs(funcs).
+		Parse(strings.Repeat("\n", max(line, 0)) + text)
+}
+
+// executeTemplate Executes a prompt template with trimmed output
+func executeTemplate(t *template.Template, args map[string]string) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, args); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// renderedPrompt Template output for one set of arguments
+type renderedPrompt struct {
+	title     string
+	body      string
+	examples  []mcp.PromptMessage
+	resources []string
+}
+
+// render Executes every template of the definition.
+//
+// The instruction and example messages see the fenced arguments when given;
+// the title, resource URIs and example conditions always see the plain ones.
+func (d *promptDefinition) render(args, fenced map[string]string) (*renderedPrompt, error) {
+	var rendered renderedPrompt
+	var err error
+
+	// Translated templates replace the English ones they provide
+	body, title := d.body, d.title
+	if translation := d.translations[args[localeArgumentName]]; translation != nil {
+		if translation.body != nil {
+			body = translation.body
+		}
+		if translation.title != nil {
+			title = translation.title
+		}
+	}
+
+	text := args
+	if fenced != nil {
+		text = fenced
+	}
+
+	if rendered.body, err = executeTemplate(body, text); err != nil {
+		return nil, err
+	}
+
+	rendered.title = d.spec.Description
+	if title != nil {
+		if rendered.title, err = executeTemplate(title, args); err != nil {
+			return nil, err
+		}
 	}
-
-	return server.ServerPrompt{
-		Prompt:  prompt,
-		Handler: handler,
+
+	for _, example := range d.localeExamples(args[localeArgumentName]) {
+		if !example.matches(args) {
+			continue
+		}
+		for _, message := range example.messages {
+			content, err := executeTemplate(message.content, text)
+			if err != nil {
+				return nil, err
+			}
+			rendered.examples = append(rendered.examples, mcp.NewPromptMessage(message.role, mcp.NewTextContent(content)))
+		}
+	}
+
+	for _, resource := range d.resources {
+		uri, err := executeTemplate(resource, args)
+		if err != nil {
+			return nil, err
+		}
+		rendered.resources = append(rendered.resources, uri)
 	}
+
+	return &rendered, nil
+}
+
+// prompt MCP prompt advertising the definition and its arguments
+func (d *promptDefinition) prompt() mcp.Prompt {
+	options := []mcp.PromptOption{mcp.WithPromptDescription(d.spec.Description)}
+	for _, argument := range d.spec.Arguments {
+		argumentOptions := []mcp.ArgumentOption{mcp.ArgumentDescription(argument.Description)}
+		if argument.Required {
+			argumentOptions = append(argumentOptions, mcp.RequiredArgument())
+		}
+		options = append(options, mcp.WithArgument(argument.Name, argumentOptions...))
+	}
+	return mcp.NewPrompt(d.spec.key(), options...)
+}
+
+// handle Renders the prompt for a prompts/get request
+func (d *promptDefinition) handle(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args, err := bindArguments(d.spec.Name, d.spec.Arguments, d.policy.clean(request.Params.Arguments))
+	if err != nil {
+		return nil, err
+	}
+	args[localeArgumentName] = d.locale(args[localeArgumentName])
+	if err := d.policy.inspect(ctx, d.spec.Name, d.spec.Arguments, args); err != nil {
+		return nil, err
+	}
+
+	var attached []mcp.PromptMessage
+	for _, argument := range d.spec.Arguments {
+		if args[argument.Name] == "" {
+			continue
+		}
+		switch argument.Format {
+		case formatDiff:
+			files, err := parseUnifiedDiff(args[argument.Name])
+			if err != nil {
+				return nil, fmt.Errorf("prompt %s: %s is not a valid unified diff: %v: %w", d.spec.Name, argument.Name, err, mcp.ErrInvalidParams)
+			}
+			summary, messages := diffAttachment(files)
+			args[argument.Name] = d.policy.neutralized(summary)
+			attached = append(attached, messages...)
+		case formatGoSource:
+			summary, messages, err := goSourceAttachment(args[argument.Name])
+			if err != nil {
+				return nil, fmt.Errorf("prompt %s: %s is not valid Go source: %v: %w", d.spec.Name, argument.Name, err, mcp.ErrInvalidParams)
+			}
+			args[argument.Name] = d.policy.neutralized(summary)
+			attached = append(attached, messages...)
+		case formatStackTrace:
+			summary, messages, err := stackTraceAttachment(parseStackTrace(args[argument.Name]))
+			if err != nil {
+				return nil, err
+			}
+			args[argument.Name] = d.policy.neutralized(summary)
+			attached = append(attached, messages...)
+		}
+	}
+
+	fenced := d.policy.fenced(d.spec.Arguments, args)
+	rendered, err := d.render(args, fenced)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render prompt %s: %w", d.spec.Name, err)
+	}
+	if fenced != nil {
+		rendered.body += "\n\n" + fenceNote
+	}
+
+	// The instruction comes first, then the few-shot exchanges, then the parsed arguments and other attached resources
+	messages := []mcp.PromptMessage{
+		mcp.NewPromptMessage(d.role, mcp.NewTextContent(rendered.body)),
+	}
+	messages = append(messages, rendered.examples...)
+	messages = append(messages, attached...)
+
+	attachments, err := attachResources(ctx, rendered.resources)
+	if err != nil {
+		return nil, err
+	}
+	messages = append(messages, attachments...)
+
+	return mcp.NewGetPromptResult(rendered.title, messages), nil
+}
+
+// attachResources Embeds the listed resources that are registered on the server, skipping the rest
+func attachResources(ctx context.Context, uris []string) ([]mcp.PromptMessage, error) {
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil || len(uris) == 0 {
+		return nil, nil
+	}
+
+	registered := mcpServer.ListResources()
+	var messages []mcp.PromptMessage
+	for _, uri := range uris {
+		resource, exists := registered[uri]
+		if !exists {
+			continue
+		}
+
+		request := mcp.ReadResourceRequest{}
+		request.Params.URI = uri
+		contents, err := resource.Handler(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attached resource %s: %w", uri, err)
+		}
+
+		for _, content := range contents {
+			messages = append(messages, mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(content)))
+		}
+	}
+	return messages, nil
+}
+
+// slug Lowercase identifier form of free text, e.g. "Linear Algebra" becomes "linear_algebra"
+func slug(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
 }
@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"context"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNewPromptLibrary(t *testing.T) {
+	builtin := fstest.MapFS{
+		"tutor.md":      &fstest.MapFile{Data: []byte("---\nname: tutor\narguments:\n  - name: topic\n    default: sets\n---\nTeach {{.topic}}\n")},
+		"quiz.yaml":     &fstest.MapFile{Data: []byte("name: quiz\nrole: assistant\narguments:\n  - name: topic\ntemplate: Quiz on {{upper .topic}}\n")},
+		"notes.txt":     &fstest.MapFile{Data: []byte("not a prompt")},
+		"drafts/old.md": &fstest.MapFile{Data: []byte("{{ broken")},
+	}
+	custom := fstest.MapFS{
+		"my_tutor.yml": &fstest.MapFile{Data: []byte("name: tutor\narguments:\n  - name: topic\ntemplate: Coach {{.topic}}\n")},
+	}
+
+	tests := []struct {
+		name     string
+		sources  []fstest.MapFS
+		prompt   string
+		args     map[string]string
+		role     mcp.Role
+		text     string
+		wantName []string
+	}{
+		{name: "markdown with a default", sources: []fstest.MapFS{builtin}, prompt: "tutor", role: mcp.RoleUser, text: "Teach sets", wantName: []string{"quiz", "tutor"}},
+		{name: "yaml with a role", sources: []fstest.MapFS{builtin}, prompt: "quiz", args: map[string]string{"topic": "sets"}, role: mcp.RoleAssistant, text: "Quiz on SETS", wantName: []string{"quiz", "tutor"}},
+		{name: "later source overrides", sources: []fstest.MapFS{builtin, custom}, prompt: "tutor", args: map[string]string{"topic": "sets"}, role: mcp.RoleUser, text: "Coach sets", wantName: []string{"quiz", "tutor"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sources []fs.FS
+			for _, source := range tt.sources {
+				sources = append(sources, source)
+			}
+			library, err := NewPromptLibrary(sources...)
+			if err != nil {
+				t.Fatalf("NewPromptLibrary failed: %v", err)
+			}
+			if names := library.Names(); !slices.Equal(names, tt.wantName) {
+				t.Errorf("names = %v, want %v", names, tt.wantName)
+			}
+
+			request := mcp.GetPromptRequest{}
+			request.Params.Arguments = tt.args
+			result, err := library.prompts[tt.prompt].handle(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handle failed: %v", err)
+			}
+			message := result.Messages[0]
+			if text := message.Content.(mcp.TextContent).Text; message.Role != tt.role || text != tt.text {
+				t.Errorf("message = %s %q, want %s %q", message.Role, text, tt.role, tt.text)
+			}
+		})
+	}
+}
+
+func TestNewPromptLibraryDuplicate(t *testing.T) {
+	_, err := NewPromptLibrary(fstest.MapFS{
+		"tutor.md":   &fstest.MapFile{Data: []byte("---\nname: tutor\n---\nTeach\n")},
+		"tutor.yaml": &fstest.MapFile{Data: []byte("name: tutor\ntemplate: Teach\n")},
+	})
+	if want := `tutor.yaml: prompt "tutor" is already defined in tutor.md`; err == nil || err.Error() != want {
+		t.Errorf("NewPromptLibrary error = %v, want %q", err, want)
+	}
+}
+
+func TestParsePromptFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		text string
+		err  string
+	}{
+		{
+			name: "unclosed front matter",
+			file: "tutor.md",
+			text: "---\nname: tutor\n\nHello\n",
+			err:  "tutor.md:1: front matter is not closed with ---",
+		},
+		{
+			name: "invalid yaml in front matter",
+			file: "tutor.md",
+			text: "---\nname: tutor\narguments: [\n---\nHello\n",
+			err:  "tutor.md: yaml: line 3:",
+		},
+		{
+			name: "unknown field in front matter",
+			file: "tutor.md",
+			text: "---\nname: tutor\ncolour: blue\n---\nHello\n",
+			err:  "tutor.md: yaml: unmarshal errors:\n  line 3: field colour not found",
+		},
+		{
+			name: "invalid version",
+			file: "tutor.md",
+			text: "---\nname: tutor\n\nversion: 2\n---\nHello\n",
+			err:  `tutor.md:4: invalid version "2"`,
+		},
+		{
+			name: "unknown role",
+			file: "tutor.md",
+			text: "---\nrole: system\n---\nHello\n",
+			err:  `tutor.md:2: unknown role "system"`,
+		},
+		{
+			name: "duplicate argument",
+			file: "tutor.md",
+			text: "---\nname: tutor\narguments:\n  - name: topic\n  - name: topic\n---\nHello\n",
+			err:  `tutor.md:4: duplicate prompt argument "topic"`,
+		},
+		{
+			name: "template parse error in the body",
+			file: "tutor.md",
+			text: "---\nname: tutor\n---\nHello\n{{ if }}\n",
+			err:  "tutor.md:5:",
+		},
+		{
+			name: "unknown variable in the body",
+			file: "tutor.md",
+			text: "---\nname: tutor\n---\nHello\n\n{{ .topic }}\n",
+			err:  "tutor.md:6:",
+		},
+		{
+			name: "template parse error in a yaml block scalar",
+			file: "tutor.yaml",
+			text: "name: tutor\ntemplate: |\n  Hello\n  {{ end }}\n",
+			err:  "tutor.yaml:4:",
+		},
+		{
+			name: "markdown with a template field",
+			file: "tutor.md",
+			text: "---\ntemplate: Hello\n---\nHello\n",
+			err:  "tutor.md: markdown prompts take their template from the body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePromptFile(tt.file, []byte(tt.text))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parsePromptFile error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		header   string
+		body     string
+		bodyLine int
+	}{
+		{name: "no front matter", content: "Hello\n", body: "Hello\n"},
+		{name: "front matter", content: "---\nname: a\n---\nHello\n", header: "\nname: a\n", body: "Hello\n", bodyLine: 3},
+		{name: "empty front matter", content: "---\n---\nHello", header: "\n", body: "Hello", bodyLine: 2},
+		{name: "delimiters with trailing spaces", content: "--- \r\nname: a\r\n---  \r\nHello\r\n", header: "\nname: a\r\n", body: "Hello\r\n", bodyLine: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, body, bodyLine, err := splitFrontMatter("p.md", tt.content)
+			if err != nil {
+				t.Fatalf("splitFrontMatter failed: %v", err)
+			}
+			if header != tt.header || body != tt.body || bodyLine != tt.bodyLine {
+				t.Errorf("splitFrontMatter = %q, %q, %d, want %q, %q, %d", header, body, bodyLine, tt.header, tt.body, tt.bodyLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// promptReloadDebounce Window in which changes to prompt files are coalesced into one reload
+const promptReloadDebounce = 250 * time.Millisecond
+
+// promptSet A loaded prompt library with the server prompts it provides, keyed by name
+type promptSet struct {
+	library *PromptLibrary
+	prompts map[string]server.ServerPrompt
+}
+
+// PromptRegistry Prompt library registered on a server and reloaded from its sources on SIGHUP or when the prompt directory changes.
+//
+// The server holds handlers that dispatch to the current set, so a reload
+// swaps every prompt at once and only prompts whose name, description or
+// arguments changed are re-registered.
+type PromptRegistry struct {
+	sources     []fs.FS
+	variants    string
+	enabled     []string
+	policy      *ArgumentPolicy
+	mcpServer   *server.MCPServer
+	completions *Completions
+	logger      *slog.Logger
+	mu          sync.Mutex
+	current     atomic.Pointer[promptSet]
+	// templates Registry the preview templates are kept in, nil unless ServePreviews was called
+	templates *ResourceTemplates
+}
+
+// NewPromptRegistry Loads the prompts of the sources, applies the variant configuration and argument policy and registers them on the server.
+//
+// Only the prompts named in enabled are served, or all of them when enabled is nil.
+func NewPromptRegistry(
+	mcpServer *server.MCPServer,
+	completions *Completions,
+	variants string,
+	enabled []string,
+	policy *ArgumentPolicy,
+	logger *slog.Logger,
+	sources ...fs.FS,
+) (*PromptRegistry, error) {
+	r := &PromptRegistry{
+		sources:     sources,
+		variants:    variants,
+		enabled:     enabled,
+		policy:      policy,
+		mcpServer:   mcpServer,
+		completions: completions,
+		logger:      logger,
+	}
+
+	set, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.current.Store(set)
+
+	mcpServer.AddPrompts(r.dispatch(sortedKeys(set.prompts))...)
+	set.library.RegisterCompletions(completions)
+	return r, nil
+}
+
+// ServePreviews Registers the prompt:// preview templates of the served prompts in a template registry, and replaces them on every reload that changes the prompts
+func (r *PromptRegistry) ServePreviews(templates *ResourceTemplates) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates = templates
+	r.templates.Set(previewTemplateGroup, r.previews(sortedKeys(r.current.Load().prompts))...)
+}
+
+// Names Names of the prompts served
+func (r *PromptRegistry) Names() []string {
+	return sortedKeys(r.current.Load().prompts)
+}
+
+// Library Prompt library currently served
+func (r *PromptRegistry) Library() *PromptLibrary {
+	return r.current.Load().library
+}
+
+// load Builds a prompt set from the sources
+func (r *PromptRegistry) load() (*promptSet, error) {
+	library, err := NewPromptLibrary(r.sources...)
+	if err != nil {
+		return nil, err
+	}
+	if err := library.ConfigureVariants(r.variants, r.logger); err != nil {
+		return nil, fmt.Errorf("invalid prompt variants: %w", err)
+	}
+	library.ConfigureArguments(r.policy)
+
+	set := &promptSet{
+		library: library,
+		prompts: make(map[string]server.ServerPrompt),
+	}
+	for _, prompt := range library.Prompts() {
+		if r.enabled != nil && !slices.Contains(r.enabled, prompt.Prompt.Name) {
+			continue
+		}
+		set.prompts[prompt.Prompt.Name] = prompt
+	}
+	return set, nil
+}
+
+// dispatch Server prompts whose handlers look the prompt up in the set current at request time
+func (r *PromptRegistry) dispatch(names []string) []server.ServerPrompt {
+	set := r.current.Load()
+	prompts := make([]server.ServerPrompt, 0, len(names))
+	for _, name := range names {
+		prompts = append(prompts, server.ServerPrompt{
+			Prompt: set.prompts[name].Prompt,
+			Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				prompt, exists := r.current.Load().prompts[name]
+				if !exists {
+					return nil, fmt.Errorf("prompt %q was removed by a reload: %w", name, server.ErrPromptNotFound)
+				}
+				return prompt.Handler(ctx, request)
+			},
+		})
+	}
+	return prompts
+}
+
+// previews Preview resource templates of the named prompts of the current set
+func (r *PromptRegistry) previews(names []string) []server.ServerResourceTemplate {
+	set := r.current.Load()
+	prompts := make([]mcp.Prompt, 0, len(names))
+	for _, name := range names {
+		prompts = append(prompts, set.prompts[name].Prompt)
+	}
+	return PromptPreviewTemplates(r.completions, prompts...)
+}
+
+// Reload Reloads the prompts from the sources and updates the server with the difference.
+//
+// A source that fails to load leaves the current prompts in place.
+func (r *PromptRegistry) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		return err
+	}
+	previous := r.current.Load()
+
+	var changed, removed []string
+	updated := 0
+	for name, prompt := range next.prompts {
+		old, exists := previous.prompts[name]
+		switch {
+		case !exists:
+			changed = append(changed, name)
+		case !reflect.DeepEqual(old.Prompt, prompt.Prompt):
+			changed = append(changed, name)
+			updated++
+		}
+	}
+	for name := range previous.prompts {
+		if _, exists := next.prompts[name]; !exists {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(removed)
+
+	// Handlers already registered serve the new definitions from here on
+	r.current.Store(next)
+
+	for name := range previous.prompts {
+		r.completions.RemovePrompt(name)
+	}
+	next.library.RegisterCompletions(r.completions)
+
+	// AddPrompts and DeletePrompts each broadcast notifications/prompts/list_changed
+	if len(changed) > 0 {
+		r.mcpServer.AddPrompts(r.dispatch(changed)...)
+	}
+	if len(removed) > 0 {
+		r.mcpServer.DeletePrompts(removed...)
+	}
+	// The previews follow, so those of removed prompts leave resources/templates/list
+	if r.templates != nil && len(changed)+len(removed) > 0 {
+		r.templates.Set(previewTemplateGroup, r.previews(sortedKeys(next.prompts))...)
+	}
+
+	r.logger.Info("Prompts reloaded",
+		"prompts", len(next.prompts),
+		"added", len(changed)-updated,
+		"updated", updated,
+		"removed", len(removed),
+	)
+	return nil
+}
+
+// Run Reloads the prompts on SIGHUP, and when files below dir change if dir is set, until ctx is cancelled
+func (r *PromptRegistry) Run(ctx context.Context, dir string) error {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	// Nil channels block forever, so without a directory only the signal triggers reloads
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	var watcher *fsnotify.Watcher
+	if dir != "" {
+		w, err := fsnotify.NewWatcher()
+		if err != nil {
+			r.logger.Warn("Prompt file notifications unavailable, reload with SIGHUP instead", "error", err)
+		} else {
+			defer w.Close()
+			if err := watchDirs(w, dir); err != nil {
+				return err
+			}
+			watcher, events, errs = w, w.Events, w.Errors
+		}
+	}
+
+	var flush <-chan time.Time
+	var timer *time.Timer
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hangup:
+			r.reload("signal")
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			if timer == nil {
+				timer = time.NewTimer(promptReloadDebounce)
+			} else {
+				timer.Reset(promptReloadDebounce)
+			}
+			flush = timer.C
+		case err, ok := <-errs:
+			if !ok {
+				return nil
+			}
+			r.logger.Error("Prompt watcher error", "error", err)
+		case <-flush:
+			flush = nil
+			// New translation directories need their own watch; inotify is not recursive
+			if err := watchDirs(watcher, dir); err != nil {
+				r.logger.Error("Failed to watch prompt directories", "error", err)
+			}
+			r.reload("file change")
+		}
+	}
+}
+
+// reload Reloads the prompts, logging a failure instead of returning it
+func (r *PromptRegistry) reload(trigger string) {
+	if err := r.Reload(); err != nil {
+		r.logger.Error("Failed to reload prompts, keeping the loaded ones", "trigger", trigger, "error", err)
+	}
+}
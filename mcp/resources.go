@@ -4,14 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// SystemStatusResource System status resource providing server status information
-func SystemStatusResource() server.ServerResource {
+// startTime Process start time used to report uptime
+var startTime = time.Now()
+
+// SystemStatusResource System status resource providing server status information, reporting the configured server name and version and the templates in the registry
+func SystemStatusResource(name, version string, sessions *SessionTracker, templates *ResourceTemplates) server.ServerResource {
 	resource := mcp.NewResource(
 		"system://status",
 		"System Status",
@@ -21,20 +28,23 @@ func SystemStatusResource() server.ServerResource {
 
 	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 		now := time.Now()
+		uptime := now.Sub(startTime)
 
 		status := map[string]interface{}{
 			"timestamp":   now.Format(time.RFC3339),
-			"server_name": "Tutorial MCP Server",
-			"version":     "1.0.0",
+			"server_name": name,
+			"version":     version,
+			"go_version":  runtime.Version(),
 			"status":      "operational",
 			"uptime_info": map[string]interface{}{
-				"current_time": now.Format("2006-01-02 15:04:05 MST"),
-				"unix_time":    now.Unix(),
-			},
-			"capabilities": []string{
-				"calculator",
-				"system_info",
+				"started_at":     startTime.Format(time.RFC3339),
+				"current_time":   now.Format("2006-01-02 15:04:05 MST"),
+				"unix_time":      now.Unix(),
+				"uptime":         uptime.Round(time.Second).String(),
+				"uptime_seconds": int64(uptime.Seconds()),
 			},
+			"capabilities":    registeredCapabilities(server.ServerFromContext(ctx), templates),
+			"active_sessions": sessions.ActiveSessions(),
 		}
 
 		content, err := json.MarshalIndent(status, "", "  ")
@@ -57,6 +67,81 @@ func SystemStatusResource() server.ServerResource {
 	}
 }
 
+// BuildVersion Module version from the embedded build info, falling back to the VCS revision; the default server version
+func BuildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return "devel+" + setting.Value
+		}
+	}
+
+	return "devel"
+}
+
+// registeredCapabilities Names of the tools, prompts, resources and resource templates registered on the server.
+//
+// mcp-go does not list resource templates, so they are taken from the registry.
+func registeredCapabilities(mcpServer *server.MCPServer, templates *ResourceTemplates) map[string][]string {
+	capabilities := map[string][]string{
+		"tools":              {},
+		"prompts":            {},
+		"resources":          {},
+		"resource_templates": templates.URITemplates(),
+	}
+	if mcpServer == nil {
+		return capabilities
+	}
+
+	for name := range mcpServer.ListTools() {
+		capabilities["tools"] = append(capabilities["tools"], name)
+	}
+	for name := range mcpServer.ListPrompts() {
+		capabilities["prompts"] = append(capabilities["prompts"], name)
+	}
+	for uri := range mcpServer.ListResources() {
+		capabilities["resources"] = append(capabilities["resources"], uri)
+	}
+
+	for _, names := range capabilities {
+		sort.Strings(names)
+	}
+
+	return capabilities
+}
+
+// mathConstants Common mathematical constants keyed by name
+var mathConstants = map[string]interface{}{
+	"pi": map[string]interface{}{
+		"symbol":      "π",
+		"value":       3.141592653589793,
+		"description": "The ratio of a circle's circumference to its diameter",
+	},
+	"e": map[string]interface{}{
+		"symbol":      "e",
+		"value":       2.718281828459045,
+		"description": "Euler's number, the base of natural logarithm",
+	},
+	"phi": map[string]interface{}{
+		"symbol":      "φ",
+		"value":       1.618033988749895,
+		"description": "The golden ratio",
+	},
+	"sqrt2": map[string]interface{}{
+		"symbol":      "√2",
+		"value":       1.4142135623730951,
+		"description": "The square root of 2",
+	},
+}
+
 // MathConstantsResource Math constants resource with common mathematical constants
 func MathConstantsResource() server.ServerResource {
 	resource := mcp.NewResource(
@@ -67,30 +152,7 @@ func MathConstantsResource() server.ServerResource {
 	)
 
 	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-		constants := map[string]interface{}{
-			"pi": map[string]interface{}{
-				"symbol":      "π",
-				"value":       3.141592653589793,
-				"description": "The ratio of a circle's circumference to its diameter",
-			},
-			"e": map[string]interface{}{
-				"symbol":      "e",
-				"value":       2.718281828459045,
-				"description": "Euler's number, the base of natural logarithm",
-			},
-			"phi": map[string]interface{}{
-				"symbol":      "φ",
-				"value":       1.618033988749895,
-				"description": "The golden ratio",
-			},
-			"sqrt2": map[string]interface{}{
-				"symbol":      "√2",
-				"value":       1.4142135623730951,
-				"description": "The square root of 2",
-			},
-		}
-
-		content, err := json.MarshalIndent(constants, "", "  ")
+		content, err := json.MarshalIndent(mathConstants, "", "  ")
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal constants: %w", err)
 		}
@@ -109,3 +171,63 @@ func MathConstantsResource() server.ServerResource {
 		Handler:  handler,
 	}
 }
+
+// MathConstantTemplate Math constant resource template returning a single constant by name
+func MathConstantTemplate(completions *Completions) server.ServerResourceTemplate {
+	const uriTemplate = "math://constants/{name}"
+
+	template := mcp.NewResourceTemplate(
+		uriTemplate,
+		"Mathematical Constant",
+		mcp.WithTemplateDescription("A single mathematical constant by name (pi, e, phi, sqrt2)"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	names := make([]string, 0, len(mathConstants))
+	for name := range mathConstants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	completions.AddResourceArgument(uriTemplate, "name", PrefixCompletion(names...))
+
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		name := templateArgument(request, "name")
+
+		constant, exists := mathConstants[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown constant %q, valid names are %s: %w",
+				name, strings.Join(names, ", "), server.ErrResourceNotFound)
+		}
+
+		content, err := json.MarshalIndent(constant, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal constant: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(content),
+			},
+		}, nil
+	}
+
+	return server.ServerResourceTemplate{
+		Template: template,
+		Handler:  handler,
+	}
+}
+
+// templateArgument Value of a variable matched from a resource template URI
+func templateArgument(request mcp.ReadResourceRequest, name string) string {
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, "/")
+	default:
+		return ""
+	}
+}
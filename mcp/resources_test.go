@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSystemStatusResource(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(false, true))
+	completions := NewCompletions()
+	templates := NewResourceTemplates(mcpServer, completions, nil)
+	templates.Add("math_constants", MathConstantTemplate(completions))
+	mcpServer.AddResources(SystemStatusResource("Tutor", "v1.2.3", NewSessionTracker(), templates))
+
+	request := []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": "system://status"}}`)
+	encoded, err := json.Marshal(mcpServer.HandleMessage(context.Background(), request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Result struct {
+			Contents []mcp.TextResourceContents `json:"contents"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(encoded, &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Result.Contents) != 1 {
+		t.Fatalf("response %s is not one resource", encoded)
+	}
+
+	var status struct {
+		ServerName   string              `json:"server_name"`
+		Version      string              `json:"version"`
+		Capabilities map[string][]string `json:"capabilities"`
+	}
+	if err := json.Unmarshal([]byte(response.Result.Contents[0].Text), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.ServerName != "Tutor" || status.Version != "v1.2.3" {
+		t.Errorf("status reports %s %s, want the configured Tutor v1.2.3", status.ServerName, status.Version)
+	}
+	if want := []string{"system://status"}; !slices.Equal(status.Capabilities["resources"], want) {
+		t.Errorf("resources = %v, want %v", status.Capabilities["resources"], want)
+	}
+	if want := []string{"math://constants/{name}"}; !slices.Equal(status.Capabilities["resource_templates"], want) {
+		t.Errorf("resource templates = %v, want %v", status.Capabilities["resource_templates"], want)
+	}
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// SessionTracker Session tracker counting active client sessions per transport
+type SessionTracker struct {
+	mu     sync.Mutex
+	active map[string]map[string]struct{}
+}
+
+// NewSessionTracker Creates an empty session tracker
+func NewSessionTracker() *SessionTracker {
+	return &SessionTracker{
+		active: make(map[string]map[string]struct{}),
+	}
+}
+
+// Register Attaches the tracker to the session lifecycle hooks of a server
+func (t *SessionTracker) Register(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		transport := sessionTransport(session)
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.active[transport] == nil {
+			t.active[transport] = make(map[string]struct{})
+		}
+		t.active[transport][session.SessionID()] = struct{}{}
+	})
+
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		transport := sessionTransport(session)
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.active[transport], session.SessionID())
+	})
+}
+
+// ActiveSessions Number of active sessions keyed by transport
+func (t *SessionTracker) ActiveSessions() map[string]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := make(map[string]int, len(t.active))
+	for transport, sessions := range t.active {
+		counts[transport] = len(sessions)
+	}
+	return counts
+}
+
+// sessionTransport maps a session to the transport that created it. mcp-go
+// keeps its session types unexported, so the concrete type name is the only
+// signal available.
+func sessionTransport(session server.ClientSession) string {
+	switch fmt.Sprintf("%T", session) {
+	case "*server.stdioSession":
+		return "stdio"
+	case "*server.sseSession":
+		return "sse"
+	case "*server.streamableHttpSession":
+		return "streamable_http"
+	case "*server.InProcessSession":
+		return "in_process"
+	default:
+		return "other"
+	}
+}
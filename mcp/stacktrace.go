@@ -0,0 +1,258 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxStackFrames Number of top frames attached from a stack trace
+const maxStackFrames = 10
+
+// stackTraceURI URI of the embedded resource holding the top frames
+const stackTraceURI = "stacktrace:///frames"
+
+// Line patterns of the supported stack trace formats
+var (
+	goGoroutinePattern = regexp.MustCompile(`^goroutine (\d+) \[([^\]]*)\]:$`)
+	goFunctionPattern  = regexp.MustCompile(`^(?:created by )?(\S.*?)(?:\([^()]*\))?(?: in goroutine \d+)?$`)
+	goLocationPattern  = regexp.MustCompile(`^\s+(\S.*?):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	pythonFramePattern = regexp.MustCompile(`^\s*File "(.+)", line (\d+)(?:, in (.+))?$`)
+	jsFramePattern     = regexp.MustCompile(`^\s*at (?:async )?(?:(.+?) \()?(.+?):(\d+):(\d+)\)?$`)
+	jsGeckoPattern     = regexp.MustCompile(`^(.*)@(.+?):(\d+):(\d+)$`)
+)
+
+// stackFrame A single call site of a stack trace; Library marks runtime, standard library and dependency code
+type stackFrame struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Library  bool   `json:"library"`
+}
+
+// stackTrace An error with its frames, most recent call first
+type stackTrace struct {
+	Language   string       `json:"language,omitempty"`
+	Kind       string       `json:"kind"`
+	Message    string       `json:"message"`
+	Goroutines int          `json:"goroutines,omitempty"`
+	Frames     []stackFrame `json:"frames"`
+}
+
+// parseStackTrace Parses a Go panic or goroutine dump, Python traceback or JavaScript stack trace.
+//
+// Text in none of these formats is kept as a plain error message without frames.
+func parseStackTrace(text string) *stackTrace {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for _, parse := range []func([]string) *stackTrace{parseGoTrace, parsePythonTrace, parseJSTrace} {
+		if trace := parse(lines); trace != nil && len(trace.Frames) > 0 {
+			return trace
+		}
+	}
+	return &stackTrace{Kind: "error message", Message: strings.TrimSpace(text)}
+}
+
+// parseGoTrace Frames of the first goroutine of a Go panic, fatal error or goroutine dump
+func parseGoTrace(lines []string) *stackTrace {
+	trace := &stackTrace{Language: "Go", Kind: "goroutine dump"}
+	var messages []string
+	inFirst := false
+	function := ""
+
+	for _, line := range lines {
+		switch {
+		case goGoroutinePattern.MatchString(line):
+			trace.Goroutines++
+			inFirst = trace.Goroutines == 1
+			function = ""
+		case trace.Goroutines == 0:
+			for _, kind := range []string{"panic: ", "fatal error: "} {
+				if strings.HasPrefix(line, kind) {
+					trace.Kind = strings.TrimSuffix(kind, ": ")
+					messages = append(messages, strings.TrimPrefix(line, kind))
+				}
+			}
+		case !inFirst || strings.TrimSpace(line) == "":
+		case goLocationPattern.MatchString(line) && function != "":
+			match := goLocationPattern.FindStringSubmatch(line)
+			lineNumber, _ := strconv.Atoi(match[2])
+			trace.Frames = append(trace.Frames, stackFrame{
+				Function: function,
+				File:     match[1],
+				Line:     lineNumber,
+				Library: strings.HasPrefix(function, "runtime.") || function == "panic" ||
+					strings.Contains(match[1], "/go/src/") || strings.Contains(match[1], "/pkg/mod/"),
+			})
+			function = ""
+		case !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, " "):
+			// Lines that name no function, such as ones starting with other whitespace, are skipped
+			if match := goFunctionPattern.FindStringSubmatch(line); match != nil {
+				function = match[1]
+			}
+		}
+	}
+
+	if trace.Goroutines == 0 {
+		return nil
+	}
+	trace.Message = strings.Join(messages, "\n")
+	return trace
+}
+
+// parsePythonTrace Frames of the last traceback in the output, which names the exception that ended the program
+func parsePythonTrace(lines []string) *stackTrace {
+	trace := &stackTrace{Language: "Python", Kind: "traceback"}
+	found := false
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "Traceback (most recent call last):") {
+			// Chained exceptions print one traceback each; the last one is raised
+			found = true
+			trace.Frames = nil
+			trace.Message = ""
+			continue
+		}
+		if !found {
+			continue
+		}
+
+		if match := pythonFramePattern.FindStringSubmatch(line); match != nil {
+			lineNumber, _ := strconv.Atoi(match[2])
+			frame := stackFrame{
+				Function: match[3],
+				File:     match[1],
+				Line:     lineNumber,
+				Library: strings.Contains(match[1], "site-packages") || strings.Contains(match[1], "/lib/python") ||
+					strings.HasPrefix(match[1], "<frozen"),
+			}
+			if i+1 < len(lines) && isPythonSourceLine(lines[i+1]) {
+				frame.Source = strings.TrimSpace(lines[i+1])
+				i++
+			}
+			trace.Frames = append(trace.Frames, frame)
+			continue
+		}
+
+		if line != "" && !strings.HasPrefix(line, " ") && trace.Message == "" && len(trace.Frames) > 0 {
+			trace.Message = line
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	slices.Reverse(trace.Frames)
+	return trace
+}
+
+// isPythonSourceLine Reports whether a traceback line is the source of the frame above it rather than a caret marker
+func isPythonSourceLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(line, "    ") && trimmed != "" && !pythonFramePattern.MatchString(line) &&
+		strings.Trim(trimmed, "^~") != ""
+}
+
+// parseJSTrace Frames of a V8 (Node.js, Chrome) or Gecko (Firefox) stack trace
+func parseJSTrace(lines []string) *stackTrace {
+	trace := &stackTrace{Language: "JavaScript", Kind: "stack trace"}
+	var messages []string
+
+	for _, line := range lines {
+		var function, file, lineText, columnText string
+		if match := jsFramePattern.FindStringSubmatch(line); match != nil {
+			function, file, lineText, columnText = match[1], match[2], match[3], match[4]
+		} else if match := jsGeckoPattern.FindStringSubmatch(line); match != nil {
+			function, file, lineText, columnText = match[1], match[2], match[3], match[4]
+		} else {
+			if len(trace.Frames) == 0 && strings.TrimSpace(line) != "" {
+				messages = append(messages, strings.TrimSpace(line))
+			}
+			continue
+		}
+
+		lineNumber, _ := strconv.Atoi(lineText)
+		column, _ := strconv.Atoi(columnText)
+		trace.Frames = append(trace.Frames, stackFrame{
+			Function: function,
+			File:     file,
+			Line:     lineNumber,
+			Column:   column,
+			Library:  strings.HasPrefix(file, "node:") || strings.Contains(file, "node_modules") || strings.HasPrefix(file, "internal/"),
+		})
+	}
+
+	trace.Message = strings.Join(messages, "\n")
+	return trace
+}
+
+// origin First frame in application code, where debugging usually starts
+func (t *stackTrace) origin() (stackFrame, bool) {
+	for _, frame := range t.Frames {
+		if !frame.Library {
+			return frame, true
+		}
+	}
+	return stackFrame{}, false
+}
+
+// String Location of the frame as function (file:line)
+func (f stackFrame) String() string {
+	location := fmt.Sprintf("%s:%d", f.File, f.Line)
+	if f.Column > 0 {
+		location = fmt.Sprintf("%s:%d", location, f.Column)
+	}
+	if f.Function == "" {
+		return location
+	}
+	return fmt.Sprintf("%s (%s)", f.Function, location)
+}
+
+// stackTraceAttachment Summary of a parsed stack trace and an embedded resource with its top frames
+func stackTraceAttachment(trace *stackTrace) (string, []mcp.PromptMessage, error) {
+	if len(trace.Frames) == 0 {
+		return trace.Message, nil, nil
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "%s %s: %s\n", trace.Language, trace.Kind, trace.Message)
+	if trace.Goroutines > 1 {
+		fmt.Fprintf(&summary, "Goroutines: %d, frames of the first one are attached\n", trace.Goroutines)
+	}
+	if frame, ok := trace.origin(); ok {
+		fmt.Fprintf(&summary, "First application frame: %s\n", frame)
+	}
+
+	top := *trace
+	top.Frames = trace.Frames[:min(len(trace.Frames), maxStackFrames)]
+	truncated := len(top.Frames) < len(trace.Frames)
+	fmt.Fprintf(&summary, "Frames: %d, the top %d are attached, most recent call first", len(trace.Frames), len(top.Frames))
+	if truncated {
+		summary.WriteString(" [truncated]")
+	}
+
+	content, err := json.MarshalIndent(top, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal stack frames: %w", err)
+	}
+
+	message := mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+		Meta: map[string]any{
+			"language":  trace.Language,
+			"kind":      trace.Kind,
+			"frames":    len(trace.Frames),
+			"truncated": truncated,
+		},
+		URI:      stackTraceURI,
+		MIMEType: "application/json",
+		Text:     string(content),
+	}))
+	return summary.String(), []mcp.PromptMessage{message}, nil
+}
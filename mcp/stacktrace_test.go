@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStackTrace(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		language   string
+		kind       string
+		message    string
+		goroutines int
+		frames     []stackFrame
+	}{
+		{
+			name: "go panic",
+			text: "panic: runtime error: index out of range [3] with length 3\n\n" +
+				"goroutine 1 [running]:\n" +
+				"main.lookup({0xc000012345, 0x3, 0x3}, 0x3)\n" +
+				"\t/app/main.go:12 +0x1d\n" +
+				"main.main()\n" +
+				"\t/app/main.go:20 +0x45\n" +
+				"exit status 2\n",
+			language:   "Go",
+			kind:       "panic",
+			message:    "runtime error: index out of range [3] with length 3",
+			goroutines: 1,
+			frames: []stackFrame{
+				{Function: "main.lookup", File: "/app/main.go", Line: 12},
+				{Function: "main.main", File: "/app/main.go", Line: 20},
+			},
+		},
+		{
+			name: "go goroutine dump keeps the first goroutine",
+			text: "goroutine 7 [chan receive]:\n" +
+				"runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)\n" +
+				"\t/usr/local/go/src/runtime/proc.go:398 +0xce\n" +
+				"example.com/app/worker.(*Pool).run(0xc0000a0000)\n" +
+				"\t/app/worker/pool.go:41 +0x85\n" +
+				"created by example.com/app/worker.New in goroutine 1\n" +
+				"\t/app/worker/pool.go:22 +0x6a\n" +
+				"\n" +
+				"goroutine 8 [select]:\n" +
+				"main.other()\n" +
+				"\t/app/main.go:5 +0x1\n",
+			language:   "Go",
+			kind:       "goroutine dump",
+			goroutines: 2,
+			frames: []stackFrame{
+				{Function: "runtime.gopark", File: "/usr/local/go/src/runtime/proc.go", Line: 398, Library: true},
+				{Function: "example.com/app/worker.(*Pool).run", File: "/app/worker/pool.go", Line: 41},
+				{Function: "example.com/app/worker.New", File: "/app/worker/pool.go", Line: 22},
+			},
+		},
+		{
+			name: "go function line with a leading carriage return",
+			text: "panic: boom\n\ngoroutine 1 [running]:\n\rmain.main()\n\t/app/main.go:12 +0x1d\n",
+			// The unmatched function line is skipped, so its location has no frame
+			kind:       "error message",
+			message:    "panic: boom\n\ngoroutine 1 [running]:\n\rmain.main()\n\t/app/main.go:12 +0x1d",
+			goroutines: 0,
+		},
+		{
+			name: "python chained traceback keeps the last one",
+			text: "Traceback (most recent call last):\n" +
+				"  File \"/app/load.py\", line 3, in load\n" +
+				"    return json.loads(text)\n" +
+				"ValueError: bad json\n" +
+				"\n" +
+				"During handling of the above exception, another exception occurred:\n" +
+				"\n" +
+				"Traceback (most recent call last):\n" +
+				"  File \"/app/main.py\", line 10, in <module>\n" +
+				"    main()\n" +
+				"  File \"/app/main.py\", line 7, in main\n" +
+				"    config = load(path)\n" +
+				"             ^^^^^^^^^^\n" +
+				"  File \"/usr/lib/python3.12/json/__init__.py\", line 346, in loads\n" +
+				"    return _default_decoder.decode(s)\n" +
+				"RuntimeError: config unreadable\n",
+			language: "Python",
+			kind:     "traceback",
+			message:  "RuntimeError: config unreadable",
+			frames: []stackFrame{
+				{Function: "loads", File: "/usr/lib/python3.12/json/__init__.py", Line: 346, Source: "return _default_decoder.decode(s)", Library: true},
+				{Function: "main", File: "/app/main.py", Line: 7, Source: "config = load(path)"},
+				{Function: "<module>", File: "/app/main.py", Line: 10, Source: "main()"},
+			},
+		},
+		{
+			name: "v8 stack trace",
+			text: "TypeError: Cannot read properties of undefined (reading 'id')\n" +
+				"    at getUser (/app/src/users.js:14:22)\n" +
+				"    at async handler (/app/src/routes.js:8:5)\n" +
+				"    at Layer.handle (/app/node_modules/express/lib/router/layer.js:95:5)\n" +
+				"    at node:internal/process/task_queues:95:5\n",
+			language: "JavaScript",
+			kind:     "stack trace",
+			message:  "TypeError: Cannot read properties of undefined (reading 'id')",
+			frames: []stackFrame{
+				{Function: "getUser", File: "/app/src/users.js", Line: 14, Column: 22},
+				{Function: "handler", File: "/app/src/routes.js", Line: 8, Column: 5},
+				{Function: "Layer.handle", File: "/app/node_modules/express/lib/router/layer.js", Line: 95, Column: 5, Library: true},
+				{File: "node:internal/process/task_queues", Line: 95, Column: 5, Library: true},
+			},
+		},
+		{
+			name: "gecko stack trace",
+			text: "render@https://example.com/app.js:120:9\n" +
+				"@https://example.com/app.js:3:1\n",
+			language: "JavaScript",
+			kind:     "stack trace",
+			frames: []stackFrame{
+				{Function: "render", File: "https://example.com/app.js", Line: 120, Column: 9},
+				{File: "https://example.com/app.js", Line: 3, Column: 1},
+			},
+		},
+		{
+			name:    "plain message",
+			text:    "  connection refused  \n",
+			kind:    "error message",
+			message: "connection refused",
+		},
+		{
+			name:    "malformed lines",
+			text:    "goroutine x [running]:\n\f\n\tat nowhere\nFile \"a.py\", line\n",
+			kind:    "error message",
+			message: "goroutine x [running]:\n\f\n\tat nowhere\nFile \"a.py\", line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := parseStackTrace(tt.text)
+
+			if trace.Language != tt.language || trace.Kind != tt.kind {
+				t.Errorf("language, kind = %q, %q, want %q, %q", trace.Language, trace.Kind, tt.language, tt.kind)
+			}
+			if trace.Message != tt.message {
+				t.Errorf("message = %q, want %q", trace.Message, tt.message)
+			}
+			if trace.Goroutines != tt.goroutines {
+				t.Errorf("goroutines = %d, want %d", trace.Goroutines, tt.goroutines)
+			}
+			if len(trace.Frames) != len(tt.frames) {
+				t.Fatalf("frames = %v, want %v", trace.Frames, tt.frames)
+			}
+			for i, frame := range trace.Frames {
+				if frame != tt.frames[i] {
+					t.Errorf("frame %d = %+v, want %+v", i, frame, tt.frames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStackTraceAttachment(t *testing.T) {
+	lines := []string{"panic: boom", "", "goroutine 1 [running]:"}
+	for i := range maxStackFrames + 2 {
+		lines = append(lines, "main.f()", "\t/app/main.go:"+strings.Repeat("1", i+1)+" +0x1")
+	}
+
+	summary, messages, err := stackTraceAttachment(parseStackTrace(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("attached %d messages, want 1", len(messages))
+	}
+	for _, want := range []string{"Go panic: boom", "First application frame: main.f (/app/main.go:1)", "Frames: 12, the top 10 are attached", "[truncated]"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary %q does not contain %q", summary, want)
+		}
+	}
+
+	summary, messages, err = stackTraceAttachment(parseStackTrace("just an error"))
+	if err != nil || summary != "just an error" || messages != nil {
+		t.Errorf("plain message = %q, %v, %v, want the message alone", summary, messages, err)
+	}
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ResourceSubscriptions Resource subscription registry tracking which sessions subscribed to which URIs
+type ResourceSubscriptions struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[string]struct{}
+}
+
+// NewResourceSubscriptions Creates an empty subscription registry
+func NewResourceSubscriptions() *ResourceSubscriptions {
+	return &ResourceSubscriptions{
+		subscribers: make(map[string]map[string]struct{}),
+	}
+}
+
+// Register Attaches the registry to the subscribe, unsubscribe and session lifecycle hooks of a server
+func (r *ResourceSubscriptions) Register(hooks *server.Hooks) {
+	hooks.AddAfterSubscribe(func(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+		session := server.ClientSessionFromContext(ctx)
+		if session == nil {
+			return
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		uri := message.Params.URI
+		if r.subscribers[uri] == nil {
+			r.subscribers[uri] = make(map[string]struct{})
+		}
+		r.subscribers[uri][session.SessionID()] = struct{}{}
+	})
+
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		session := server.ClientSessionFromContext(ctx)
+		if session == nil {
+			return
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.subscribers[message.Params.URI], session.SessionID())
+	})
+
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, sessions := range r.subscribers {
+			delete(sessions, session.SessionID())
+		}
+	})
+}
+
+// Subscribers Session IDs subscribed to a resource URI
+func (r *ResourceSubscriptions) Subscribers(uri string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessionIDs := make([]string, 0, len(r.subscribers[uri]))
+	for sessionID := range r.subscribers[uri] {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	return sessionIDs
+}
+
+// NotifyUpdated Sends notifications/resources/updated for a URI to every subscribed session
+func (r *ResourceSubscriptions) NotifyUpdated(mcpServer *server.MCPServer, uri string) error {
+	var errs []error
+	for _, sessionID := range r.Subscribers(uri) {
+		err := mcpServer.SendNotificationToSpecificClient(
+			sessionID,
+			mcp.MethodNotificationResourceUpdated,
+			map[string]any{"uri": uri},
+		)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
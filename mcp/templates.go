@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ResourceTemplates Resource templates registered on a server in named groups, so that one group can be replaced without the others.
+//
+// mcp-go can add resource templates or replace all of them, but not remove
+// one, so replacing a group that drops a template registers every group again.
+// Templates are recorded in the metrics too, which count their reads by
+// template name.
+type ResourceTemplates struct {
+	mcpServer   *server.MCPServer
+	completions *Completions
+	metrics     *Metrics
+	mu          sync.Mutex
+	groups      map[string][]server.ServerResourceTemplate
+}
+
+// NewResourceTemplates Creates an empty template registry for a server, recording templates in the metrics, if any
+func NewResourceTemplates(mcpServer *server.MCPServer, completions *Completions, metrics *Metrics) *ResourceTemplates {
+	return &ResourceTemplates{
+		mcpServer:   mcpServer,
+		completions: completions,
+		metrics:     metrics,
+		groups:      make(map[string][]server.ServerResourceTemplate),
+	}
+}
+
+// Add Registers templates in a group
+func (t *ResourceTemplates) Add(group string, templates ...server.ServerResourceTemplate) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.groups[group] = append(t.groups[group], templates...)
+	t.mcpServer.AddResourceTemplates(templates...)
+	t.metrics.AddResourceTemplates(templates...)
+}
+
+// URITemplates URI templates of every group, sorted, or none for a nil registry
+func (t *ResourceTemplates) URITemplates() []string {
+	uris := []string{}
+	if t == nil {
+		return uris
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, templates := range t.groups {
+		for _, template := range templates {
+			uris = append(uris, template.Template.URITemplate.Raw())
+		}
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// Set Replaces the templates of a group, unregistering those it no longer contains along with their completions
+func (t *ResourceTemplates) Set(group string, templates ...server.ServerResourceTemplate) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	kept := make(map[string]bool, len(templates))
+	for _, template := range templates {
+		kept[template.Template.URITemplate.Raw()] = true
+	}
+	var dropped []string
+	for _, template := range t.groups[group] {
+		if raw := template.Template.URITemplate.Raw(); !kept[raw] {
+			dropped = append(dropped, raw)
+		}
+	}
+	t.groups[group] = templates
+
+	if len(dropped) == 0 {
+		t.mcpServer.AddResourceTemplates(templates...)
+		t.metrics.AddResourceTemplates(templates...)
+		return
+	}
+
+	var all []server.ServerResourceTemplate
+	for _, name := range sortedKeys(t.groups) {
+		all = append(all, t.groups[name]...)
+	}
+	t.mcpServer.SetResourceTemplates(all...)
+	t.metrics.SetResourceTemplates(all...)
+	for _, raw := range dropped {
+		t.completions.RemoveResource(raw)
+	}
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// listTemplateURIs URI templates the server lists in resources/templates/list, sorted
+func listTemplateURIs(t *testing.T, mcpServer *server.MCPServer) []string {
+	t.Helper()
+	request := []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/templates/list"}`)
+	encoded, err := json.Marshal(mcpServer.HandleMessage(context.Background(), request))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var response struct {
+		Result mcp.ListResourceTemplatesResult `json:"result"`
+	}
+	if err := json.Unmarshal(encoded, &response); err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, template := range response.Result.ResourceTemplates {
+		uris = append(uris, template.URITemplate.Raw())
+	}
+	slices.Sort(uris)
+	return uris
+}
+
+func TestPromptPreviewsFollowReloads(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt := func(name string) {
+		t.Helper()
+		text := "---\nname: " + name + "\narguments:\n  - name: topic\n---\nTeach {{.topic}}\n"
+		if err := os.WriteFile(filepath.Join(dir, name+".md"), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePrompt("algebra")
+	writePrompt("geometry")
+
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(false, true))
+	completions := NewCompletions()
+	metrics := NewMetrics(nil)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	prompts, err := NewPromptRegistry(mcpServer, completions, "", nil, nil, logger, os.DirFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates := NewResourceTemplates(mcpServer, completions, metrics)
+	templates.Add("math_constants", MathConstantTemplate(completions))
+	prompts.ServePreviews(templates)
+
+	const constant = "math://constants/{name}"
+	want := []string{constant, "prompt://algebra{?topic,locale,format}", "prompt://geometry{?topic,locale,format}"}
+	if uris := listTemplateURIs(t, mcpServer); !slices.Equal(uris, want) {
+		t.Fatalf("templates = %v, want %v", uris, want)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "geometry.md")); err != nil {
+		t.Fatal(err)
+	}
+	writePrompt("calculus")
+	if err := prompts.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The other groups stay registered when the previews are replaced
+	want = []string{constant, "prompt://algebra{?topic,locale,format}", "prompt://calculus{?topic,locale,format}"}
+	if uris := listTemplateURIs(t, mcpServer); !slices.Equal(uris, want) {
+		t.Errorf("templates after reload = %v, want %v", uris, want)
+	}
+	if got := metrics.resourceName(context.Background(), "prompt://geometry?topic=x"); got != "prompt://" {
+		t.Errorf("metrics still count reads of the removed preview as %q", got)
+	}
+	if completions.resources["prompt://geometry{?topic,locale,format}"] != nil {
+		t.Error("completions of the removed preview are still registered")
+	}
+}
@@ -0,0 +1,533 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// goMIMEType MIME type of Go source attachments
+const goMIMEType = "text/x-go"
+
+// goReservedFields Table fields the scaffold uses itself, which parameters must not shadow
+var goReservedFields = map[string]bool{"name": true, "receiver": true, "wantErr": true}
+
+// goMajorVersionPattern Major version suffix of a module path, e.g. v2
+var goMajorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// goImporter Resolves imports from the export data of the Go installation once per process.
+//
+// Export data is read in a fraction of a second per package, unlike type
+// checking GOROOT sources. The importer caches packages and is not safe for
+// concurrent use, so every type check holds goImporterMu. Without a Go
+// installation every import is left to lenientImporter.
+var (
+	goImporter   = sync.OnceValue(importer.Default)
+	goImporterMu sync.Mutex
+)
+
+// lenientImporter Importer standing in an empty package for imports that cannot be resolved, e.g. third-party modules
+type lenientImporter struct {
+	base       types.Importer
+	unresolved []string
+}
+
+// Import Implements types.Importer
+func (i *lenientImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, err := i.base.Import(importPath); err == nil {
+		return pkg, nil
+	}
+	i.unresolved = append(i.unresolved, importPath)
+	pkg := types.NewPackage(importPath, guessPackageName(importPath))
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+// guessPackageName Likely package name of an import path, e.g. widgets for github.com/acme/widgets/v2 or yaml for gopkg.in/yaml.v3
+func guessPackageName(importPath string) string {
+	name := path.Base(importPath)
+	if dir := path.Dir(importPath); goMajorVersionPattern.MatchString(name) && dir != "." {
+		name = path.Base(dir)
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// goValue A parameter, result or receiver of a function as a table field
+type goValue struct {
+	field string
+	typ   string
+	zero  string
+}
+
+// goFunction An exported function or method and how its test calls it
+type goFunction struct {
+	name      string
+	receiver  *goValue
+	signature string
+	params    []goValue
+	results   []goValue
+	variadic  bool
+	hasError  bool
+	skipped   string
+}
+
+// testName Name of the test function, TestType_Method for methods
+func (f goFunction) testName() string {
+	if f.receiver == nil {
+		return "Test" + f.name
+	}
+	return "Test" + strings.TrimPrefix(f.receiver.typ, "*") + "_" + f.name
+}
+
+// goTestScaffold Table-driven test skeletons for the exported functions of a Go file
+type goTestScaffold struct {
+	pkg        string
+	functions  []goFunction
+	code       string
+	fileName   string
+	checkError error
+	unresolved []string
+}
+
+// scaffoldGoTests Parses and type-checks Go source and writes a table-driven test skeleton per exported function.
+//
+// Imports are resolved from the Go installation's export data; types of
+// unresolved imports get *new(T) zero values, which compile for any type.
+func scaffoldGoTests(fileName, source string) (*goTestScaffold, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, source, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	goImporterMu.Lock()
+	defer goImporterMu.Unlock()
+
+	imports := &lenientImporter{base: goImporter()}
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	config := types.Config{Importer: imports, Error: func(error) {}}
+	config.Check(file.Name.Name, fset, []*ast.File{file}, info)
+
+	scaffold := &goTestScaffold{
+		pkg:        file.Name.Name,
+		fileName:   strings.TrimSuffix(path.Base(fileName), ".go") + "_test.go",
+		unresolved: imports.unresolved,
+	}
+	usedImports := make(map[string]string)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !fn.Name.IsExported() {
+			continue
+		}
+		function := describeGoFunction(fset, fn, info)
+		if function.skipped == "" {
+			collectGoImports(file, info, fn.Type, usedImports)
+			if fn.Recv != nil {
+				collectGoImports(file, info, fn.Recv, usedImports)
+			}
+		}
+		scaffold.functions = append(scaffold.functions, function)
+	}
+
+	code, err := renderGoTests(scaffold.pkg, scaffold.functions, usedImports)
+	if err != nil {
+		return nil, err
+	}
+	scaffold.code = code
+
+	// Check the scaffold together with the source so that a generator bug surfaces as a note rather than a broken file
+	if testFile, err := parser.ParseFile(fset, scaffold.fileName, code, parser.SkipObjectResolution); err == nil {
+		verify := types.Config{Importer: &lenientImporter{base: goImporter()}}
+		_, scaffold.checkError = verify.Check(file.Name.Name, fset, []*ast.File{file, testFile}, nil)
+	} else {
+		scaffold.checkError = err
+	}
+
+	return scaffold, nil
+}
+
+// describeGoFunction Signature, table fields and call shape of a function declaration
+func describeGoFunction(fset *token.FileSet, fn *ast.FuncDecl, info *types.Info) goFunction {
+	declaration := *fn
+	declaration.Doc = nil
+	declaration.Body = nil
+	var signature bytes.Buffer
+	printer.Fprint(&signature, fset, &declaration)
+
+	function := goFunction{name: fn.Name.Name, signature: signature.String()}
+
+	if fn.Type.TypeParams != nil {
+		function.skipped = "generic functions need type arguments"
+		return function
+	}
+	if fn.Recv != nil {
+		receiverType := fn.Recv.List[0].Type
+		base := receiverType
+		if star, ok := base.(*ast.StarExpr); ok {
+			base = star.X
+		}
+		ident, ok := base.(*ast.Ident)
+		switch {
+		case !ok:
+			function.skipped = "methods of generic types need type arguments"
+			return function
+		case !ident.IsExported():
+			function.skipped = "the receiver type is unexported"
+			return function
+		}
+		receiver := goValue{field: "receiver", typ: types.ExprString(receiverType)}
+		if base != receiverType {
+			receiver.zero = "new(" + ident.Name + ")"
+		} else {
+			receiver.zero = goZeroValue(receiverType, info.TypeOf(receiverType))
+		}
+		function.receiver = &receiver
+	}
+
+	used := make(map[string]bool)
+	index := 0
+	for _, field := range fn.Type.Params.List {
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{Name: "_"}}
+		}
+		for _, ident := range names {
+			name := ident.Name
+			if name == "_" {
+				name = fmt.Sprintf("arg%d", index)
+			}
+			if goReservedFields[name] || strings.HasPrefix(name, "want") || used[name] {
+				name += "Arg"
+			}
+			used[name] = true
+			index++
+
+			value := goValue{field: name, typ: types.ExprString(field.Type)}
+			if ellipsis, ok := field.Type.(*ast.Ellipsis); ok {
+				function.variadic = true
+				value.typ = "[]" + types.ExprString(ellipsis.Elt)
+				value.zero = "nil"
+			} else {
+				value.zero = goZeroValue(field.Type, info.TypeOf(field.Type))
+			}
+			function.params = append(function.params, value)
+		}
+	}
+
+	if fn.Type.Results != nil {
+		var results []ast.Expr
+		for _, field := range fn.Type.Results.List {
+			for range max(len(field.Names), 1) {
+				results = append(results, field.Type)
+			}
+		}
+		// An empty result list, as in func F() (), has no last result
+		if len(results) > 0 && types.ExprString(results[len(results)-1]) == "error" {
+			function.hasError = true
+			results = results[:len(results)-1]
+		}
+		for i, result := range results {
+			field := "want"
+			if i > 0 {
+				field += strconv.Itoa(i)
+			}
+			function.results = append(function.results, goValue{
+				field: field,
+				typ:   types.ExprString(result),
+				zero:  goZeroValue(result, info.TypeOf(result)),
+			})
+		}
+	}
+
+	return function
+}
+
+// goZeroValue Zero value literal of a type, or *new(T) when the type could not be resolved
+func goZeroValue(expr ast.Expr, t types.Type) string {
+	text := types.ExprString(expr)
+	if t == nil {
+		return "*new(" + text + ")"
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		case u.Kind() == types.UnsafePointer:
+			return "nil"
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil"
+	case *types.Struct, *types.Array:
+		return text + "{}"
+	}
+	return "*new(" + text + ")"
+}
+
+// collectGoImports Records the imports a node's package qualifiers refer to, keyed by import path with the name they are imported as
+func collectGoImports(file *ast.File, info *types.Info, node ast.Node, used map[string]string) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		qualifier, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		if pkgName, ok := info.Uses[qualifier].(*types.PkgName); ok {
+			used[pkgName.Imported().Path()] = ""
+			if pkgName.Name() != pkgName.Imported().Name() {
+				used[pkgName.Imported().Path()] = pkgName.Name()
+			}
+			return false
+		}
+
+		// The package name of an unresolved import may differ from its path; match the qualifier against the import
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			switch {
+			case spec.Name != nil && spec.Name.Name == qualifier.Name:
+				used[importPath] = spec.Name.Name
+			case spec.Name == nil && guessPackageName(importPath) == qualifier.Name:
+				used[importPath] = ""
+			}
+		}
+		return false
+	})
+}
+
+// renderGoTests Formatted _test.go file with one table-driven test per function that is not skipped
+func renderGoTests(pkg string, functions []goFunction, imports map[string]string) (string, error) {
+	var b strings.Builder
+	compares := false
+	for _, function := range functions {
+		if function.skipped == "" && len(function.results) > 0 {
+			compares = true
+		}
+	}
+	imports["testing"] = ""
+	if compares {
+		imports["reflect"] = ""
+	}
+
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", pkg)
+	// Standard library imports come first, separated from the others by a blank line
+	var standard, others []string
+	for _, importPath := range sortedKeys(imports) {
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			others = append(others, importPath)
+		} else {
+			standard = append(standard, importPath)
+		}
+	}
+	for i, group := range [][]string{standard, others} {
+		if i > 0 && len(group) > 0 {
+			b.WriteString("\n")
+		}
+		for _, importPath := range group {
+			if name := imports[importPath]; name != "" {
+				fmt.Fprintf(&b, "%s %q\n", name, importPath)
+			} else {
+				fmt.Fprintf(&b, "%q\n", importPath)
+			}
+		}
+	}
+	b.WriteString(")\n")
+
+	for _, function := range functions {
+		if function.skipped != "" {
+			continue
+		}
+		writeGoTest(&b, function)
+	}
+
+	code, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format test scaffold: %w", err)
+	}
+	return string(code), nil
+}
+
+// writeGoTest Writes the table-driven test of one function
+func writeGoTest(b *strings.Builder, function goFunction) {
+	fields := []goValue{{field: "name", typ: "string", zero: `"TODO: describe the case"`}}
+	if function.receiver != nil {
+		fields = append(fields, *function.receiver)
+	}
+	fields = append(fields, function.params...)
+	fields = append(fields, function.results...)
+	if function.hasError {
+		fields = append(fields, goValue{field: "wantErr", typ: "bool", zero: "false"})
+	}
+
+	fmt.Fprintf(b, "\nfunc %s(t *testing.T) {\ntests := []struct {\n", function.testName())
+	for _, field := range fields {
+		fmt.Fprintf(b, "%s %s\n", field.field, field.typ)
+	}
+	b.WriteString("}{\n{\n")
+	for _, field := range fields {
+		fmt.Fprintf(b, "%s: %s,\n", field.field, field.zero)
+	}
+	b.WriteString("},\n}\nfor _, tt := range tests {\nt.Run(tt.name, func(t *testing.T) {\n")
+
+	var got []string
+	for i := range function.results {
+		if i == 0 {
+			got = append(got, "got")
+		} else {
+			got = append(got, "got"+strconv.Itoa(i))
+		}
+	}
+	if function.hasError {
+		got = append(got, "err")
+	}
+
+	var args []string
+	for _, param := range function.params {
+		args = append(args, "tt."+param.field)
+	}
+	if function.variadic {
+		args[len(args)-1] += "..."
+	}
+	call := function.name + "(" + strings.Join(args, ", ") + ")"
+	if function.receiver != nil {
+		call = "tt.receiver." + call
+	}
+
+	if len(got) > 0 {
+		fmt.Fprintf(b, "%s := %s\n", strings.Join(got, ", "), call)
+	} else {
+		fmt.Fprintf(b, "%s\n", call)
+	}
+	if function.hasError {
+		fmt.Fprintf(b, "if (err != nil) != tt.wantErr {\nt.Fatalf(\"%s() error = %%v, wantErr %%v\", err, tt.wantErr)\n}\n", function.name)
+	}
+	for i, result := range function.results {
+		fmt.Fprintf(b, "if !reflect.DeepEqual(%s, tt.%s) {\nt.Errorf(\"%s() %s = %%v, want %%v\", %s, tt.%s)\n}\n",
+			got[i], result.field, function.name, got[i], got[i], result.field)
+	}
+	b.WriteString("})\n}\n}\n")
+}
+
+// listing Exported functions with their signatures and notes on skipped functions and type checking
+func (s *goTestScaffold) listing() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Exported functions in package %s:\n", s.pkg)
+	if len(s.functions) == 0 {
+		b.WriteString("- none\n")
+	}
+	for _, function := range s.functions {
+		fmt.Fprintf(&b, "- %s", function.signature)
+		if function.skipped != "" {
+			fmt.Fprintf(&b, " (skipped: %s)", function.skipped)
+		}
+		b.WriteString("\n")
+	}
+
+	switch {
+	case len(s.unresolved) > 0:
+		fmt.Fprintf(&b, "\nImports %s could not be resolved; their types use *new(T) zero values.", strings.Join(s.unresolved, ", "))
+	case s.checkError != nil:
+		fmt.Fprintf(&b, "\nThe scaffold does not type-check against the source: %v", s.checkError)
+	default:
+		fmt.Fprintf(&b, "\nThe scaffold %s type-checks against the source.", s.fileName)
+	}
+	return b.String()
+}
+
+// goSourceAttachment Summary of a Go file's exported functions, with the source and its test scaffold as embedded resources
+func goSourceAttachment(source string) (string, []mcp.PromptMessage, error) {
+	scaffold, err := scaffoldGoTests("source.go", source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	messages := []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+			URI:      "go:///source.go",
+			MIMEType: goMIMEType,
+			Text:     source,
+		})),
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+			Meta:     map[string]any{"package": scaffold.pkg, "scaffold": true},
+			URI:      "go:///" + scaffold.fileName,
+			MIMEType: goMIMEType,
+			Text:     scaffold.code,
+		})),
+	}
+	return scaffold.listing(), messages, nil
+}
+
+// GoTestScaffoldTool Go test scaffold tool writing table-driven test skeletons for the exported functions of a Go file of at most maxSourceBytes
+func GoTestScaffoldTool(maxSourceBytes int) server.ServerTool {
+	tool := mcp.NewTool("go_test_scaffold",
+		mcp.WithDescription("List the exported functions of a Go source file and generate table-driven test skeletons with typed zero-value inputs"),
+		mcp.WithString("source",
+			mcp.Description("Go source file text"),
+			mcp.Required(),
+		),
+		mcp.WithString("file_name",
+			mcp.Description("Name of the source file, used to name the test file (default source.go)"),
+		),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		source, err := request.RequireString("source")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if len(source) > maxSourceBytes {
+			return mcp.NewToolResultError(fmt.Sprintf("source is %d bytes, larger than the %d byte limit", len(source), maxSourceBytes)), nil
+		}
+		fileName := request.GetString("file_name", "source.go")
+		if !strings.HasSuffix(fileName, ".go") || strings.HasSuffix(fileName, "_test.go") {
+			return mcp.NewToolResultError("file_name must name a non-test .go file"), nil
+		}
+
+		scaffold, err := scaffoldGoTests(fileName, source)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse Go source: %v", err)), nil
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.NewTextContent(scaffold.listing()),
+				mcp.NewTextContent(scaffold.code),
+			},
+		}, nil
+	}
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: handler,
+	}
+}
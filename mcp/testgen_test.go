@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScaffoldGoTests(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		skipped []string
+	}{
+		{
+			name:   "empty result list",
+			source: "package a\n\nfunc F() () {}\n",
+			want:   []string{"func TestF(t *testing.T) {", "\t\t\tF()\n"},
+		},
+		{
+			name:   "no results",
+			source: "package a\n\nfunc Run(n int) {}\n",
+			want:   []string{"func TestRun(t *testing.T) {", "n    int", "Run(tt.n)"},
+		},
+		{
+			name:   "error result",
+			source: "package a\n\nfunc Parse(s string) (int, error) { return 0, nil }\n",
+			want: []string{
+				"want    int",
+				"wantErr bool",
+				"got, err := Parse(tt.s)",
+				"if (err != nil) != tt.wantErr {",
+				"if !reflect.DeepEqual(got, tt.want) {",
+			},
+		},
+		{
+			name:   "error only",
+			source: "package a\n\nfunc Check() error { return nil }\n",
+			want:   []string{"err := Check()", "wantErr bool"},
+		},
+		{
+			name:   "variadic",
+			source: "package a\n\nfunc Sum(base int, values ...int) int { return base }\n",
+			want:   []string{"values []int", "values: nil,", "got := Sum(tt.base, tt.values...)"},
+		},
+		{
+			name: "methods",
+			source: "package a\n\ntype Counter struct{ n int }\n\n" +
+				"func (c *Counter) Add(n int) int { c.n += n; return c.n }\n\n" +
+				"func (c Counter) Value() (count int) { return c.n }\n\n" +
+				"type hidden struct{}\n\nfunc (hidden) Do() {}\n",
+			want: []string{
+				"func TestCounter_Add(t *testing.T) {",
+				"receiver: new(Counter),",
+				"got := tt.receiver.Add(tt.n)",
+				"func TestCounter_Value(t *testing.T) {",
+				"receiver: Counter{},",
+			},
+			skipped: []string{"the receiver type is unexported"},
+		},
+		{
+			name:    "generic",
+			source:  "package a\n\nfunc Map[T any](v T) T { return v }\n\nfunc Len(s string) int { return len(s) }\n",
+			want:    []string{"got := Len(tt.s)"},
+			skipped: []string{"generic functions need type arguments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scaffold, err := scaffoldGoTests("a.go", tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if scaffold.checkError != nil {
+				t.Errorf("scaffold does not type-check: %v\n%s", scaffold.checkError, scaffold.code)
+			}
+			if scaffold.fileName != "a_test.go" {
+				t.Errorf("file name = %q, want a_test.go", scaffold.fileName)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(scaffold.code, want) {
+					t.Errorf("scaffold does not contain %q:\n%s", want, scaffold.code)
+				}
+			}
+			listing := scaffold.listing()
+			for _, reason := range tt.skipped {
+				if !strings.Contains(listing, "(skipped: "+reason+")") {
+					t.Errorf("listing does not skip with %q:\n%s", reason, listing)
+				}
+			}
+		})
+	}
+}
+
+func TestScaffoldGoTestsUnresolvedImports(t *testing.T) {
+	source := "package a\n\n" +
+		"import (\n" +
+		"\t\"net/http\"\n\n" +
+		"\t\"github.com/acme/widgets/v2\"\n" +
+		"\tstore \"example.com/missing/db\"\n" +
+		")\n\n" +
+		"func Handle(w widgets.Widget, r *http.Request) error { return nil }\n\n" +
+		"func Save(c store.Conn, ids []widgets.ID) (store.Result, error) { return store.Result{}, nil }\n"
+
+	scaffold, err := scaffoldGoTests("a.go", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(scaffold.unresolved, ","); got != "github.com/acme/widgets/v2,example.com/missing/db" {
+		t.Errorf("unresolved imports = %q", got)
+	}
+	for _, want := range []string{
+		`store "example.com/missing/db"`,
+		`"github.com/acme/widgets/v2"`,
+		`"net/http"`,
+		"w: *new(widgets.Widget),",
+		"r: nil,",
+		"c: *new(store.Conn),",
+		"want: *new(store.Result),",
+		"got, err := Save(tt.c, tt.ids)",
+	} {
+		// Fields are aligned by gofmt, so spacing is compared loosely
+		if !strings.Contains(strings.Join(strings.Fields(scaffold.code), " "), want) {
+			t.Errorf("scaffold does not contain %q:\n%s", want, scaffold.code)
+		}
+	}
+	// Stubbed packages declare nothing, so the listing notes the imports rather than a type-check error
+	listing := scaffold.listing()
+	if !strings.Contains(listing, "Imports github.com/acme/widgets/v2, example.com/missing/db could not be resolved") {
+		t.Errorf("listing does not mention the unresolved imports:\n%s", listing)
+	}
+	if strings.Contains(listing, "does not type-check") {
+		t.Errorf("listing reports a type-check error:\n%s", listing)
+	}
+}
+
+func TestGuessPackageName(t *testing.T) {
+	tests := map[string]string{
+		"github.com/acme/widgets/v2":  "widgets",
+		"gopkg.in/yaml.v3":            "yaml",
+		"github.com/mattn/go-sqlite3": "sqlite3",
+		"example.com/foo-bar":         "foo_bar",
+		"v2":                          "v2",
+	}
+	for importPath, want := range tests {
+		if got := guessPackageName(importPath); got != want {
+			t.Errorf("guessPackageName(%q) = %q, want %q", importPath, got, want)
+		}
+	}
+}
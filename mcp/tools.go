@@ -11,8 +11,8 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// CalculatorTool Calculator tool for basic math operations
-func CalculatorTool() server.ServerTool {
+// CalculatorTool Calculator tool for basic math operations, giving results with precision decimal places
+func CalculatorTool(precision int) server.ServerTool {
 	tool := mcp.NewTool("calculator",
 		mcp.WithDescription("Perform basic mathematical calculations"),
 		mcp.WithString("operation",
@@ -88,7 +88,7 @@ func CalculatorTool() server.ServerTool {
 		// Format the result
 		var resultStr string
 		if operation == "sqrt" {
-			resultStr = fmt.Sprintf("√%.2f = %.6f", firstNum, result)
+			resultStr = fmt.Sprintf("√%.2f = %.*f", firstNum, precision, result)
 		} else {
 			secondNum, _ := request.RequireFloat("second_number")
 			var operatorSymbol string
@@ -104,7 +104,7 @@ func CalculatorTool() server.ServerTool {
 			case "power":
 				operatorSymbol = "^"
 			}
-			resultStr = fmt.Sprintf("%.2f %s %.2f = %.6f", firstNum, operatorSymbol, secondNum, result)
+			resultStr = fmt.Sprintf("%.2f %s %.2f = %.*f", firstNum, operatorSymbol, secondNum, precision, result)
 		}
 
 		return mcp.NewToolResultText(resultStr), nil
@@ -116,8 +116,8 @@ func CalculatorTool() server.ServerTool {
 	}
 }
 
-// SystemInfoTool System info tool for time and date information
-func SystemInfoTool() server.ServerTool {
+// SystemInfoTool System info tool for time and date information in the given location
+func SystemInfoTool(location *time.Location) server.ServerTool {
 	tool := mcp.NewTool("system_info",
 		mcp.WithDescription("Get system information like current time and date"),
 		mcp.WithString("info_type",
@@ -140,7 +140,7 @@ func SystemInfoTool() server.ServerTool {
 
 		format := request.GetString("format", "human")
 
-		now := time.Now()
+		now := time.Now().In(location)
 		var result string
 
 		switch infoType {
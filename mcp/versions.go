@@ -0,0 +1,261 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// promptVersionPattern Version of a prompt, e.g. v2
+var promptVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// promptVariant A version of a prompt and its share of sessions when served through the alias
+type promptVariant struct {
+	version string
+	weight  int
+}
+
+// key Name the prompt is registered under, name@version for versioned prompts
+func (s promptSpec) key() string {
+	if s.Version == "" {
+		return s.Name
+	}
+	return s.Name + "@" + s.Version
+}
+
+// sortVersions Sorts versions numerically, so v10 follows v9
+func sortVersions(versions []string) {
+	slices.SortFunc(versions, func(a, b string) int {
+		x, _ := strconv.Atoi(strings.TrimPrefix(a, "v"))
+		y, _ := strconv.Atoi(strings.TrimPrefix(b, "v"))
+		return x - y
+	})
+}
+
+// remove Drops a prompt and all of its versions
+func (l *PromptLibrary) remove(name string) {
+	for key, definition := range l.prompts {
+		if definition.spec.Name == name {
+			delete(l.prompts, key)
+		}
+	}
+}
+
+// lookup Definition registered under a name, resolving a versioned prompt's name to its default version
+func (l *PromptLibrary) lookup(name string) (*promptDefinition, bool) {
+	if definition, exists := l.prompts[name]; exists {
+		return definition, true
+	}
+	if _, exists := l.versions[name]; exists {
+		return l.prompts[name+"@"+l.defaultVersion(name)], true
+	}
+	return nil, false
+}
+
+// targets Definitions a file named after a prompt applies to: the named version, or every version of an unversioned name
+func (l *PromptLibrary) targets(name string) []*promptDefinition {
+	if definition, exists := l.prompts[name]; exists {
+		return []*promptDefinition{definition}
+	}
+	var definitions []*promptDefinition
+	for _, key := range sortedKeys(l.prompts) {
+		if definition := l.prompts[key]; definition.spec.Name == name {
+			definitions = append(definitions, definition)
+		}
+	}
+	return definitions
+}
+
+// checkVersionArguments Fails unless every version of a prompt declares the same arguments.
+//
+// The alias advertises a single argument list but may serve any version, so
+// versions may only differ in the descriptions of their arguments and in
+// their translations.
+func (l *PromptLibrary) checkVersionArguments() error {
+	for _, name := range sortedKeys(l.versions) {
+		versions := l.versions[name]
+		first := l.prompts[name+"@"+versions[0]]
+		for _, version := range versions[1:] {
+			definition := l.prompts[name+"@"+version]
+			if difference := argumentsDifference(first.spec.Arguments, definition.spec.Arguments); difference != "" {
+				return fmt.Errorf("%s: prompt %s@%s %s than %s in %s, but every version of a prompt must take the same arguments",
+					definition.file, name, version, difference, versions[0], first.file)
+			}
+		}
+	}
+	return nil
+}
+
+// argumentsDifference First difference between two argument declarations other than descriptions, or empty when they match
+func argumentsDifference(a, b []promptArgument) string {
+	names := func(arguments []promptArgument) string {
+		list := make([]string, len(arguments))
+		for i, argument := range arguments {
+			list[i] = argument.Name
+		}
+		return strings.Join(list, ", ")
+	}
+	if names(a) != names(b) {
+		return fmt.Sprintf("takes the arguments %s rather", names(b))
+	}
+
+	for i := range a {
+		x, y := a[i], b[i]
+		x.Description, y.Description = "", ""
+		// Locale values list the translations of each version, and any locale is accepted
+		if x.Name == localeArgumentName {
+			x.Values, y.Values = nil, nil
+		}
+		if !reflect.DeepEqual(x, y) {
+			return fmt.Sprintf("declares argument %q differently", a[i].Name)
+		}
+	}
+	return ""
+}
+
+// defaultVersion Version the alias serves without a weighted assignment: the first configured, else the oldest
+func (l *PromptLibrary) defaultVersion(name string) string {
+	if variants := l.variants[name]; len(variants) > 0 {
+		return variants[0].version
+	}
+	return l.versions[name][0]
+}
+
+// ConfigureVariants Sets the versions the unversioned prompt names resolve to and logs every versioned prompt served.
+//
+// The configuration lists prompts separated by semicolons, each as
+// name=version for a fixed default, or name=version:weight,version:weight to
+// assign every session one of the versions with the given relative weights.
+// Assignment hashes the session ID rather than drawing at random, so the
+// weights hold on average across many sessions, not for any few of them. The
+// first listed version is the default for requests without a session.
+func (l *PromptLibrary) ConfigureVariants(config string, audit *slog.Logger) error {
+	variants := make(map[string][]promptVariant)
+
+	for _, entry := range strings.Split(config, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, list, found := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return fmt.Errorf("invalid prompt variants %q, expected name=version or name=version:weight,version:weight", entry)
+		}
+		versions, exists := l.versions[name]
+		if !exists {
+			return fmt.Errorf("prompt %q has no versions", name)
+		}
+		if _, exists := variants[name]; exists {
+			return fmt.Errorf("prompt %q is configured twice", name)
+		}
+
+		for _, item := range strings.Split(list, ",") {
+			version, weightText, hasWeight := strings.Cut(strings.TrimSpace(item), ":")
+			weight := 1
+			if hasWeight {
+				w, err := strconv.Atoi(weightText)
+				if err != nil || w <= 0 {
+					return fmt.Errorf("prompt %q: invalid weight %q for %s, expected a positive integer", name, weightText, version)
+				}
+				weight = w
+			}
+			if !slices.Contains(versions, version) {
+				return fmt.Errorf("prompt %q has no version %q, available versions are %s", name, version, strings.Join(versions, ", "))
+			}
+			for _, variant := range variants[name] {
+				if variant.version == version {
+					return fmt.Errorf("prompt %q lists version %s twice", name, version)
+				}
+			}
+			variants[name] = append(variants[name], promptVariant{version: version, weight: weight})
+		}
+	}
+
+	l.variants = variants
+	l.audit = audit
+	return nil
+}
+
+// choose Version of a versioned prompt for the session of a request, with how it was chosen.
+//
+// Sessions are assigned deterministically by an FNV hash of the prompt name and
+// session ID, not at random. Session IDs are random themselves, so sessions
+// spread across the variants by weight on average over many sessions, while
+// each session keeps one variant for its whole lifetime without storing
+// assignments.
+func (l *PromptLibrary) choose(ctx context.Context, name string) (string, string) {
+	variants := l.variants[name]
+	session := server.ClientSessionFromContext(ctx)
+	if len(variants) < 2 || session == nil {
+		return l.defaultVersion(name), "default"
+	}
+
+	total := 0
+	for _, variant := range variants {
+		total += variant.weight
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(name + "\x00" + session.SessionID()))
+	point := int(hash.Sum32() % uint32(total))
+	for _, variant := range variants {
+		if point < variant.weight {
+			return variant.version, "weighted"
+		}
+		point -= variant.weight
+	}
+	return variants[len(variants)-1].version, "weighted"
+}
+
+// serve Renders a versioned prompt, naming the served variant in the description and the audit log
+func (l *PromptLibrary) serve(ctx context.Context, request mcp.GetPromptRequest, definition *promptDefinition, assignment string) (*mcp.GetPromptResult, error) {
+	result, err := definition.handle(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	variant := definition.spec.key()
+	result.Description = fmt.Sprintf("%s [%s]", result.Description, variant)
+
+	if l.audit != nil {
+		sessionID := ""
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			sessionID = session.SessionID()
+		}
+		l.audit.Info("Prompt variant served",
+			"prompt", request.Params.Name,
+			"variant", variant,
+			"assignment", assignment,
+			"session", sessionID,
+		)
+	}
+	return result, nil
+}
+
+// aliasPrompt Unversioned prompt serving the version chosen for each session
+func (l *PromptLibrary) aliasPrompt(name string) server.ServerPrompt {
+	// The alias advertises the arguments of the default version
+	prompt := l.prompts[name+"@"+l.defaultVersion(name)].prompt()
+	prompt.Name = name
+	prompt.Description = fmt.Sprintf("%s (versions: %s)", prompt.Description, strings.Join(l.versions[name], ", "))
+
+	handler := func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		version, assignment := l.choose(ctx, name)
+		return l.serve(ctx, request, l.prompts[name+"@"+version], assignment)
+	}
+
+	return server.ServerPrompt{
+		Prompt:  prompt,
+		Handler: handler,
+	}
+}
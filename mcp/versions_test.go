@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// versionedPrompt Markdown prompt file of a version with the given argument block
+func versionedPrompt(version, arguments string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte("---\nname: tutor\nversion: " + version + "\narguments:\n" + arguments + "---\nTeach {{.topic}}\n")}
+}
+
+func TestPromptVersionArguments(t *testing.T) {
+	const topic = "  - name: topic\n    description: Topic\n"
+	const level = "  - name: level\n    values: [low, high]\n    strict: true\n"
+
+	tests := []struct {
+		name string
+		v2   string
+		err  string
+	}{
+		{name: "same arguments", v2: topic + level},
+		{name: "other descriptions", v2: strings.Replace(topic, "Topic", "What to learn", 1) + level},
+		{name: "missing argument", v2: topic, err: "tutor_v2.md: prompt tutor@v2 takes the arguments topic, locale rather than v1 in tutor.md"},
+		{name: "reordered arguments", v2: level + topic, err: "takes the arguments level, topic, locale rather than v1"},
+		{name: "other values", v2: topic + strings.Replace(level, "high", "higher", 1), err: `prompt tutor@v2 declares argument "level" differently than v1`},
+		{name: "required", v2: topic + level + "    required: true\n", err: `declares argument "level" differently`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library, err := NewPromptLibrary(fstest.MapFS{
+				"tutor.md":    versionedPrompt("v1", topic+level),
+				"tutor_v2.md": versionedPrompt("v2", tt.v2),
+				// Translations of one version only do not make the versions differ
+				"locales/es/tutor@v1.md": &fstest.MapFile{Data: []byte("Enseña {{.topic}}\n")},
+			})
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("NewPromptLibrary failed: %v", err)
+				}
+				if locales := library.prompts["tutor@v1"].locales(); len(locales) != 2 {
+					t.Errorf("v1 locales = %v, want en and es", locales)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("NewPromptLibrary error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// variantSession Session with its own ID, for assigning variants
+type variantSession struct {
+	testSession
+	id string
+}
+
+func (s *variantSession) SessionID() string { return s.id }
+
+func TestPromptVariantAssignment(t *testing.T) {
+	const arguments = "  - name: topic\n"
+	library, err := NewPromptLibrary(fstest.MapFS{
+		"tutor.md":    versionedPrompt("v1", arguments),
+		"tutor_v2.md": versionedPrompt("v2", arguments),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := library.ConfigureVariants("tutor=v1:3,v2:1", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	if version, assignment := library.choose(context.Background(), "tutor"); version != "v1" || assignment != "default" {
+		t.Errorf("without a session, choose = %s, %s, want v1, default", version, assignment)
+	}
+
+	counts := make(map[string]int)
+	const sessions = 4000
+	for i := range sessions {
+		session := &variantSession{id: fmt.Sprintf("session-%d", i)}
+		ctx := mcpServer.WithContext(context.Background(), session)
+		version, assignment := library.choose(ctx, "tutor")
+		if assignment != "weighted" {
+			t.Fatalf("assignment = %s, want weighted", assignment)
+		}
+		// A session keeps its variant
+		if again, _ := library.choose(ctx, "tutor"); again != version {
+			t.Fatalf("session %s got %s, then %s", session.id, version, again)
+		}
+		counts[version]++
+	}
+
+	// The hash spreads sessions by weight on average, not exactly
+	if share := float64(counts["v1"]) / sessions; share < 0.7 || share > 0.8 {
+		t.Errorf("v1 served %.2f of sessions, want about 0.75 (%v)", share, counts)
+	}
+
+	result, err := library.serve(context.Background(), mcp.GetPromptRequest{Params: mcp.GetPromptParams{Name: "tutor"}}, library.prompts["tutor@v2"], "explicit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(result.Description, "[tutor@v2]") {
+		t.Errorf("description %q does not name the variant", result.Description)
+	}
+}
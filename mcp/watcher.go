@@ -0,0 +1,206 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// FileWatcher File watcher keeping file resources registered on a server in sync with the filesystem
+type FileWatcher struct {
+	files         *FileResources
+	mcpServer     *server.MCPServer
+	subscriptions *ResourceSubscriptions
+	debounce      time.Duration
+	logger        *slog.Logger
+	watcher       *fsnotify.Watcher
+	current       map[string]fileEntry
+}
+
+// NewFileWatcher Creates a watcher that registers the current files and coalesces changes within the debounce window
+func NewFileWatcher(
+	files *FileResources,
+	mcpServer *server.MCPServer,
+	subscriptions *ResourceSubscriptions,
+	debounce time.Duration,
+	logger *slog.Logger,
+) (*FileWatcher, error) {
+	w := &FileWatcher{
+		files:         files,
+		mcpServer:     mcpServer,
+		subscriptions: subscriptions,
+		debounce:      debounce,
+		logger:        logger,
+	}
+
+	// Watch before the initial scan so that no change slips in between the two
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Warn("File notifications unavailable, polling instead", "error", err, "interval", debounce)
+	} else {
+		if err := watchDirs(watcher, files.Root()); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+		w.watcher = watcher
+	}
+
+	current, err := files.scan()
+	if err != nil {
+		if w.watcher != nil {
+			w.watcher.Close()
+		}
+		return nil, err
+	}
+
+	resources := make([]server.ServerResource, 0, len(current))
+	for _, file := range current {
+		resources = append(resources, file.resource)
+	}
+	mcpServer.AddResources(resources...)
+	w.current = current
+
+	return w, nil
+}
+
+// Files Number of file resources currently registered
+func (w *FileWatcher) Files() int {
+	return len(w.current)
+}
+
+// Run Watches the resource root until ctx is cancelled, falling back to polling when inotify is unavailable
+func (w *FileWatcher) Run(ctx context.Context) error {
+	if w.watcher == nil {
+		return w.poll(ctx)
+	}
+	watcher := w.watcher
+	defer watcher.Close()
+
+	// A nil channel blocks forever, so the timer case only fires once a change is pending
+	var flush <-chan time.Time
+	var timer *time.Timer
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			if timer == nil {
+				timer = time.NewTimer(w.debounce)
+			} else {
+				timer.Reset(w.debounce)
+			}
+			flush = timer.C
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.logger.Error("File watcher error", "error", err)
+		case <-flush:
+			flush = nil
+			// New directories need their own watch; inotify is not recursive
+			if err := watchDirs(watcher, w.files.Root()); err != nil {
+				w.logger.Error("Failed to watch directories", "error", err)
+			}
+			w.sync()
+		}
+	}
+}
+
+// poll Rescans the resource root on a fixed interval
+func (w *FileWatcher) poll(ctx context.Context) error {
+	ticker := time.NewTicker(w.debounce)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.sync()
+		}
+	}
+}
+
+// watchDirs Adds a watch for every directory below root
+func watchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(p); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", p, err)
+		}
+		return nil
+	})
+}
+
+// sync Rescans the root, registers added files, re-registers changed ones, removes deleted ones and notifies subscribers of the changes.
+//
+// A sync broadcasts notifications/resources/list_changed once for removals and
+// once for additions and changes.
+func (w *FileWatcher) sync() {
+	next, err := w.files.scan()
+	if err != nil {
+		w.logger.Error("Failed to rescan file resources", "error", err)
+		return
+	}
+
+	// Changed files are registered again, since their listing carries the size and MIME type
+	var added, register []server.ServerResource
+	var changed []string
+	for uri, file := range next {
+		previous, exists := w.current[uri]
+		switch {
+		case !exists:
+			added = append(added, file.resource)
+			register = append(register, file.resource)
+		case !previous.modTime.Equal(file.modTime) || previous.size != file.size:
+			changed = append(changed, uri)
+			register = append(register, file.resource)
+		}
+	}
+
+	var removed []string
+	for uri := range w.current {
+		if _, exists := next[uri]; !exists {
+			removed = append(removed, uri)
+		}
+	}
+
+	w.current = next
+
+	// Only the workspace:/// entries are touched, so resources registered concurrently by
+	// others are kept; each call broadcasts notifications/resources/list_changed
+	if len(removed) > 0 {
+		w.mcpServer.DeleteResources(removed...)
+	}
+	if len(register) > 0 {
+		w.mcpServer.AddResources(register...)
+	}
+
+	for _, uri := range changed {
+		if err := w.subscriptions.NotifyUpdated(w.mcpServer, uri); err != nil {
+			w.logger.Warn("Failed to notify resource subscribers", "uri", uri, "error", err)
+		}
+	}
+
+	if len(added) > 0 || len(removed) > 0 || len(changed) > 0 {
+		w.logger.Info("File resources changed", "added", len(added), "removed", len(removed), "updated", len(changed))
+	}
+}
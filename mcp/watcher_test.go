@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// testSession Initialized client session collecting the notifications sent to it
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()                                         {}
+func (s *testSession) Initialized() bool                                   { return true }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *testSession) SessionID() string                                   { return "test" }
+
+// drain Methods of the notifications received so far
+func (s *testSession) drain() []string {
+	var methods []string
+	for {
+		select {
+		case notification := <-s.notifications:
+			methods = append(methods, notification.Method)
+		default:
+			return methods
+		}
+	}
+}
+
+func TestFileWatcherSync(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "a")
+	write("b.txt", "b")
+
+	files, err := NewFileResources(root, 1<<20, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(true, true))
+	mcpServer.AddResources(MathConstantsResource())
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	watcher, err := NewFileWatcher(files, mcpServer, NewResourceSubscriptions(), time.Hour, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.watcher.Close()
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 16)}
+	if err := mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func()
+		uris    []string
+		size    int64
+		notices int
+	}{
+		{
+			name:    "nothing changed",
+			change:  func() {},
+			uris:    []string{"math://constants", "workspace:///a.txt", "workspace:///b.txt"},
+			size:    1,
+			notices: 0,
+		},
+		{
+			name:    "changed file",
+			change:  func() { write("a.txt", "aaaa") },
+			uris:    []string{"math://constants", "workspace:///a.txt", "workspace:///b.txt"},
+			size:    4,
+			notices: 1,
+		},
+		{
+			name: "added, changed and removed files",
+			change: func() {
+				write("a.txt", "aaaaaaaa")
+				write("c.txt", "c")
+				if err := os.Remove(filepath.Join(root, "b.txt")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			uris:    []string{"math://constants", "workspace:///a.txt", "workspace:///c.txt"},
+			size:    8,
+			notices: 2,
+		},
+		{
+			name: "removed file",
+			change: func() {
+				if err := os.Remove(filepath.Join(root, "c.txt")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			uris:    []string{"math://constants", "workspace:///a.txt"},
+			size:    8,
+			notices: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+			watcher.sync()
+
+			resources := mcpServer.ListResources()
+			uris := sortedKeys(resources)
+			if len(uris) != len(tt.uris) {
+				t.Fatalf("resources = %v, want %v", uris, tt.uris)
+			}
+			for i := range uris {
+				if uris[i] != tt.uris[i] {
+					t.Fatalf("resources = %v, want %v", uris, tt.uris)
+				}
+			}
+			size := int64(-1)
+			if listed := resources["workspace:///a.txt"].Resource.Size; listed != nil {
+				size = *listed
+			}
+			if size != tt.size {
+				t.Errorf("a.txt is listed with size %d, want %d", size, tt.size)
+			}
+
+			listChanged := 0
+			for _, method := range session.drain() {
+				if method == mcp.MethodNotificationResourcesListChanged {
+					listChanged++
+				}
+			}
+			if listChanged != tt.notices {
+				t.Errorf("sent %d list_changed notifications, want %d", listChanged, tt.notices)
+			}
+		})
+	}
+}